@@ -31,144 +31,177 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
+	"github.com/TFMV/AddressMatchPro/internal/logging"
 	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/config"
+	"github.com/TFMV/AddressMatchPro/pkg/db"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func clearOldCandidates(pool *pgxpool.Pool) {
-	tables := []string{
-		"customer_keys",
-		"customer_tokens",
-		"tokens_idf",
-		"customer_vector_embedding",
-	}
-	for _, table := range tables {
-		query := fmt.Sprintf("DELETE FROM %s WHERE run_id = 0", table)
-		if _, err := pool.Exec(context.Background(), query); err != nil {
-			log.Fatalf("Failed to clear old candidates from %s: %v", table, err)
-		}
+// fatal logs a failure and exits with a non-zero status
+func fatal(ctx context.Context, msg string, err error) {
+	slog.ErrorContext(ctx, msg, "error", err)
+	os.Exit(1)
+}
+
+// vectorIndexOptions applies the configured index parameters over the defaults
+func vectorIndexOptions(cfg *config.Config) matcher.IndexOptions {
+	opts := matcher.DefaultIndexOptions()
+	if cfg.VectorIndex.Method != "" {
+		opts.Method = cfg.VectorIndex.Method
+	}
+	if cfg.VectorIndex.M > 0 {
+		opts.M = cfg.VectorIndex.M
+	}
+	if cfg.VectorIndex.EfConstruction > 0 {
+		opts.EfConstruction = cfg.VectorIndex.EfConstruction
 	}
+	if cfg.VectorIndex.Lists > 0 {
+		opts.Lists = cfg.VectorIndex.Lists
+	}
+	return opts
 }
 
-func clearAndInsertDefaultRun(pool *pgxpool.Pool) {
-	// Clear existing entry with run_id = 0 from runs table
-	clearQuery := "DELETE FROM runs WHERE run_id = 0"
-	if _, err := pool.Exec(context.Background(), clearQuery); err != nil {
-		log.Fatalf("Failed to clear runs for run_id = 0: %v", err)
+// evaluateVectorIndex reports recall and latency of the run 0 ANN index over a
+// ladder of ef_search (HNSW) or probes (IVFFlat) values
+func evaluateVectorIndex(ctx context.Context, pool *pgxpool.Pool, cfg *config.Config, samples int, k int) {
+	opts := vectorIndexOptions(cfg)
+	settings := []int{10, 20, 40, 80, 160, 320}
+	configured := cfg.VectorIndex.EfSearch
+	if opts.Method == matcher.IndexMethodIVFFlat {
+		settings = []int{1, 2, 5, 10, 20, 50}
+		configured = cfg.VectorIndex.Probes
+	}
+	if configured > 0 {
+		settings = append(settings, configured)
 	}
 
-	// Insert default run_id = 0 into runs table
-	insertQuery := "INSERT INTO runs (run_id, description) VALUES (0, 'Default run')"
-	if _, err := pool.Exec(context.Background(), insertQuery); err != nil {
-		log.Fatalf("Failed to insert default run: %v", err)
+	reports, err := matcher.EvaluateVectorIndex(ctx, pool, opts.Method, settings, samples, k)
+	if err != nil {
+		fatal(ctx, "failed to evaluate vector index", err)
+	}
+
+	fmt.Printf("%-8s %8s %8s %12s %12s %12s %12s\n", "method", "setting", "recall", "ann_mean", "ann_p95", "exact_mean", "exact_p95")
+	for _, r := range reports {
+		fmt.Printf("%-8s %8d %8.4f %12v %12v %12v %12v\n", r.Method, r.Setting, r.Recall, r.ANNLatency, r.ANNP95, r.ExactLatency, r.ExactP95)
 	}
-	fmt.Println("Default run inserted successfully")
 }
 
-func syncCustomerMatchingWithRun(pool *pgxpool.Pool) {
-	// Clear existing entries in customer_matching with run_id = 0
-	clearQuery := "DELETE FROM customer_matching WHERE run_id = 0"
-	if _, err := pool.Exec(context.Background(), clearQuery); err != nil {
-		log.Fatalf("Failed to clear customer_matching for run_id = 0: %v", err)
+// printHouseholds groups the candidate space into households and prints one line per member
+func printHouseholds(ctx context.Context, pool *pgxpool.Pool, req matcher.HouseholdRequest) {
+	opts := req.Options(matcher.DefaultMatchOptions())
+	households, err := matcher.FindHouseholds(ctx, pool, req, opts)
+	if err != nil {
+		fatal(ctx, "failed to find households", err)
 	}
 
-	// Insert rows into customer_matching with run_id = 0
-	insertQuery := `
-		INSERT INTO customer_matching (customer_id, first_name, last_name, phone_number, street, city, state, zip_code, run_id)
-		SELECT customer_id, LOWER(customer_fname), LOWER(customer_lname), NULL AS phone_number, LOWER(customer_street), LOWER(customer_city), LOWER(customer_state), LOWER(customer_zipcode::TEXT), 0 AS run_id
-		FROM customers;
-	`
-	if _, err := pool.Exec(context.Background(), insertQuery); err != nil {
-		log.Fatalf("Failed to insert into customer_matching for run_id = 0: %v", err)
+	fmt.Printf("%-12s %-12s %-40s %-8s %s\n", "household_id", "customer_id", "street", "unit", "name")
+	for _, h := range households {
+		for _, m := range h.Members {
+			fmt.Printf("%-12d %-12d %-40s %-8s %s %s\n", h.HouseholdID, m.CustomerID, h.Street, h.Unit, m.FirstName, m.LastName)
+		}
 	}
-	fmt.Println("Customer matching table synced with run_id = 0")
+	fmt.Printf("%d households\n", len(households))
 }
 
 func main() {
-	start := time.Now()
+	migrate := flag.Bool("migrate", false, "apply pending database migrations before building the candidate space")
+	evaluateIndex := flag.Bool("evaluate-index", false, "report vector index recall and latency instead of rebuilding the candidate space")
+	evaluateSamples := flag.Int("evaluate-samples", 100, "number of sampled queries for -evaluate-index")
+	evaluateK := flag.Int("evaluate-k", 10, "neighbours compared per query for -evaluate-index")
+	households := flag.Bool("households", false, "print the households of the candidate space instead of rebuilding it")
+	householdSurname := flag.Bool("household-surname", false, "require surname agreement within a household")
+	householdState := flag.String("household-state", "", "limit -households to a state")
+	householdZip := flag.String("household-zip", "", "limit -households to a zip code")
+	householdMinScore := flag.Float64("household-min-score", matcher.DefaultHouseholdMinScore, "address score needed to share a household")
+	tenant := flag.String("tenant", matcher.DefaultTenant, "tenant whose candidate space is built or inspected")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctx := matcher.WithTenant(context.Background(), *tenant)
 
-	// Load the configuration
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/config.yaml" // Default path for local development
-	}
+	start := time.Now()
 
-	config, err := matcher.LoadConfig(configPath)
+	// Load the configuration: file, then AMP_* environment variables, then -set flags
+	cfg, err := configFlags.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-	fmt.Println("Config loaded successfully")
-
-	// Create the database connection string
-	databaseUrl := fmt.Sprintf(
-		"postgresql://%s:%s@%s:%s/%s",
-		config.DBCreds.Username,
-		config.DBCreds.Password,
-		config.DBCreds.Host,
-		config.DBCreds.Port,
-		config.DBCreds.Database,
-	)
-
-	// Parse the database connection string
-	dbConfig, err := pgxpool.ParseConfig(databaseUrl)
-	if err != nil {
-		log.Fatalf("Unable to parse DATABASE_URL: %v\n", err)
+		fatal(ctx, "failed to load configuration", err)
 	}
+	if configFlags.Print {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fatal(ctx, "failed to print configuration", err)
+		}
+		return
+	}
+	if err := logging.Setup(os.Stderr, logging.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+		LogPII: cfg.Logging.LogPII,
+	}); err != nil {
+		fatal(ctx, "invalid logging configuration", err)
+	}
+	slog.InfoContext(ctx, "config loaded")
 
 	// Create the connection pool
-	pool, err := pgxpool.NewWithConfig(context.Background(), dbConfig)
+	pool, err := db.NewConnection(db.DBCreds{
+		Host:     cfg.DBCreds.Host,
+		Port:     cfg.DBCreds.Port,
+		Username: cfg.DBCreds.Username,
+		Password: cfg.DBCreds.Password,
+		Database: cfg.DBCreds.Database,
+	})
 	if err != nil {
-		log.Fatalf("Unable to create connection pool: %v\n", err)
+		fatal(ctx, "failed to create database connection pool", err)
 	}
 	defer pool.Close()
-	fmt.Println("Database connection pool created successfully")
-
-	// Clear existing run_id = 0 and insert default run into runs table
-	stepStart := time.Now()
-	clearAndInsertDefaultRun(pool)
-	fmt.Printf("Default run inserted in %v\n", time.Since(stepStart))
+	slog.InfoContext(ctx, "database connection pool created")
 
-	// Clear old candidates with run_id = 0
-	stepStart = time.Now()
-	clearOldCandidates(pool)
-	fmt.Printf("Old candidates cleared in %v\n", time.Since(stepStart))
-
-	// Sync customer_matching table with run_id = 0
-	stepStart = time.Now()
-	syncCustomerMatchingWithRun(pool)
-	fmt.Printf("Customer matching table synced in %v\n", time.Since(stepStart))
+	// Verify (or apply) the schema migrations embedded in the binary
+	if err := db.EnsureSchema(ctx, pool, *migrate); err != nil {
+		fatal(ctx, "database schema check failed", err)
+	}
 
-	// Load reference entities once
-	stepStart = time.Now()
-	referenceEntities := matcher.LoadReferenceEntities(pool)
-	fmt.Printf("Reference entities loaded in %v\n", time.Since(stepStart))
+	if *evaluateIndex {
+		evaluateVectorIndex(ctx, pool, cfg, *evaluateSamples, *evaluateK)
+		return
+	}
 
-	// Process customer addresses and generate binary keys with concurrency
-	stepStart = time.Now()
-	matcher.ProcessCustomerAddresses(pool, referenceEntities, 10, 0) // Passing run_id = 0
-	fmt.Printf("Customer addresses processed in %v\n", time.Since(stepStart))
+	if *households {
+		printHouseholds(ctx, pool, matcher.HouseholdRequest{
+			MinScore:     householdMinScore,
+			MatchSurname: *householdSurname,
+			State:        *householdState,
+			ZipCode:      *householdZip,
+		})
+		return
+	}
 
-	// Generate TF/IDF vectors
-	stepStart = time.Now()
-	matcher.GenerateTFIDF(pool, 0) // Passing run_id = 0
-	fmt.Printf("TF/IDF vectors generated in %v\n", time.Since(stepStart))
+	// Replace the tenant's run 0 records with its customers
+	stepStart := time.Now()
+	synced, err := matcher.SyncCandidateRecords(ctx, pool)
+	if err != nil {
+		fatal(ctx, "failed to sync customers into run 0", err)
+	}
+	slog.InfoContext(ctx, "customers synced into run 0", "customers", synced, "elapsed", time.Since(stepStart))
 
-	// Insert vector embeddings using Python script
+	// Rebuild the keys, TF/IDF vectors, embeddings and ANN index of run 0
 	stepStart = time.Now()
-	scriptPath := os.Getenv("SCRIPT_PATH")
-	if scriptPath == "" {
-		scriptPath = "/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/python-ml/generate_embeddings.py"
+	embedder := matcher.PythonEmbedder{
+		ScriptPath: cfg.Embedding.ScriptPath,
+		Python:     cfg.Embedding.Python,
+		Env:        cfg.DBCreds.PGEnv(),
 	}
-	if err := matcher.GenerateEmbeddingsPythonScript(scriptPath, 0); err != nil {
-		log.Fatalf("Failed to generate embeddings: %v", err)
+	if err := matcher.RebuildCandidateSpace(ctx, pool, cfg.Pipeline.Workers, embedder, vectorIndexOptions(cfg)); err != nil {
+		fatal(ctx, "failed to rebuild the candidate space", err)
 	}
-	fmt.Printf("Vector embeddings generated in %v\n", time.Since(stepStart))
+	slog.InfoContext(ctx, "candidate space rebuilt", "elapsed", time.Since(stepStart))
 
-	fmt.Printf("Total time taken: %v\n", time.Since(start))
+	slog.InfoContext(ctx, "finished", "elapsed", time.Since(start))
 }
 
@@ -0,0 +1,213 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+// Command pprl links customer lists without exchanging cleartext names and
+// addresses. Each party encodes its CSV with a shared secret, and the
+// encodings are matched on the Dice similarity of their Bloom filters.
+//
+//	pprl encode -in customers.csv -out encoded.csv
+//	pprl match -inputs ours.csv -candidates theirs.csv -out matches.json
+//
+// The secret is read from the AMP_PPRL_SECRET environment variable or from
+// the file given with -secret-file.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+// secretEnv holds the shared secret when no secret file is given
+const secretEnv = "AMP_PPRL_SECRET"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "encode":
+		err = encode(os.Args[2:])
+	case "match":
+		err = match(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pprl encode|match [flags]")
+	os.Exit(2)
+}
+
+func encode(args []string) error {
+	fs := flag.NewFlagSet("encode", flag.ExitOnError)
+	in := fs.String("in", "", "CSV with a customer_id column and name and address columns")
+	out := fs.String("out", "", "encoded CSV to write; stdout when empty")
+	secretFile := fs.String("secret-file", "", "file holding the shared secret; overrides "+secretEnv)
+	filterBits := fs.Int("bits", matcher.DefaultFilterBits, "bits per field filter")
+	hashes := fs.Int("hashes", matcher.DefaultFilterHashes, "bits set per bigram")
+	fs.Parse(args)
+	if *in == "" {
+		return errors.New("encode: -in is required")
+	}
+
+	secret, err := loadSecret(*secretFile)
+	if err != nil {
+		return err
+	}
+	encoder, err := matcher.NewCLKEncoder(secret, *filterBits, *hashes)
+	if err != nil {
+		return err
+	}
+
+	r, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, closeOut, err := create(*out)
+	if err != nil {
+		return err
+	}
+
+	count, err := matcher.EncodeCSV(r, w, encoder)
+	if err != nil {
+		closeOut()
+		return err
+	}
+	if err := closeOut(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Encoded %d records\n", count)
+	return nil
+}
+
+func match(args []string) error {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	inputsPath := fs.String("inputs", "", "encoded CSV of the records to match")
+	candidatesPath := fs.String("candidates", "", "encoded CSV of the records to match against")
+	out := fs.String("out", "", "JSON file of the matches to write; stdout when empty")
+	topN := fs.Int("top-n", 1, "candidates kept per input record")
+	minScore := fs.Float64("min-score", 60, "smallest score kept, from 1 to 100")
+	fs.Parse(args)
+	if *inputsPath == "" || *candidatesPath == "" {
+		return errors.New("match: -inputs and -candidates are required")
+	}
+
+	inputs, err := readEncoded(*inputsPath)
+	if err != nil {
+		return err
+	}
+	candidates, err := readEncoded(*candidatesPath)
+	if err != nil {
+		return err
+	}
+
+	opts := matcher.DefaultMatchOptions().Merge(matcher.MatchOverrides{TopN: topN, MinScore: minScore})
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	matches, err := matcher.MatchEncoded(inputs, candidates, opts)
+	if err != nil {
+		return err
+	}
+
+	w, closeOut, err := create(*out)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(matches); err != nil {
+		closeOut()
+		return err
+	}
+	if err := closeOut(); err != nil {
+		return err
+	}
+	matched := make(map[int]bool)
+	for _, m := range matches {
+		matched[m.InputCustomerID] = true
+	}
+	fmt.Fprintf(os.Stderr, "Matched %d of %d input records\n", len(matched), len(inputs))
+	return nil
+}
+
+// loadSecret reads the shared secret from a file, or from the environment
+func loadSecret(path string) ([]byte, error) {
+	if path == "" {
+		secret := os.Getenv(secretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("set %s or pass -secret-file", secretEnv)
+		}
+		return []byte(secret), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret: %v", err)
+	}
+	return []byte(strings.TrimSpace(string(b))), nil
+}
+
+func readEncoded(path string) ([]matcher.EncodedRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := matcher.ReadEncoded(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// create opens the output file, or stdout when path is empty
+func create(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
@@ -30,14 +30,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/TFMV/AddressMatchPro/internal/logging"
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/internal/metrics"
+	"github.com/TFMV/AddressMatchPro/internal/tracing"
+	"github.com/TFMV/AddressMatchPro/pkg/amp"
 	"github.com/TFMV/AddressMatchPro/pkg/api"
 	"github.com/TFMV/AddressMatchPro/pkg/config"
 	"github.com/TFMV/AddressMatchPro/pkg/db"
+	"github.com/TFMV/AddressMatchPro/pkg/grpcapi"
+	"github.com/TFMV/AddressMatchPro/pkg/grpcapi/addressmatchv1"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // @title AddressMatchPro API
@@ -56,17 +76,47 @@ import (
 // @BasePath /
 
 func main() {
-	// Load configuration
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/config.yaml"
-	}
-	cfg, err := config.LoadConfig(configPath)
+	migrate := flag.Bool("migrate", false, "apply pending database migrations at startup")
+	grpcAddr := flag.String("grpc-addr", "", "gRPC listen address; overrides grpc.addr in the config")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Load the configuration: file, then AMP_* environment variables, then -set flags
+	cfg, err := configFlags.Load()
 	if err != nil {
-		log.Panic(err)
+		log.Fatal(err)
+	}
+	if configFlags.Print {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *grpcAddr != "" {
+		cfg.GRPC.Addr = *grpcAddr
+	}
+	if err := logging.Setup(os.Stdout, logging.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+		LogPII: cfg.Logging.LogPII,
+	}); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+	slog.Info("config loaded")
+
+	// SIGTERM (sent by Cloud Run on scale-down) and SIGINT start a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, *migrate); err != nil {
+		log.Fatal(err)
 	}
-	fmt.Println("Config loaded successfully")
+}
 
+// run serves the REST and gRPC APIs until ctx is done, then drains them
+// within the shutdown timeout. The pool is closed and the spans flushed
+// before it returns.
+func run(ctx context.Context, cfg *config.Config, migrate bool) error {
 	// Create the database connection pool
 	pool, err := db.NewConnection(db.DBCreds{
 		Host:     cfg.DBCreds.Host,
@@ -75,19 +125,279 @@ func main() {
 		Password: cfg.DBCreds.Password,
 		Database: cfg.DBCreds.Database,
 	})
+	if err != nil {
+		return fmt.Errorf("failed to create database connection pool: %v", err)
+	}
+	defer func() {
+		pool.Close()
+		slog.Info("database connection pool closed")
+	}()
+	slog.Info("database connection pool created")
 
+	// Verify (or apply) the schema migrations embedded in the binary
+	if err := db.EnsureSchema(ctx, pool, migrate); err != nil {
+		return fmt.Errorf("database schema check failed: %v", err)
+	}
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: "addressmatchpro",
+	})
 	if err != nil {
-		log.Fatalf("Failed to create database connection pool: %v", err)
+		return fmt.Errorf("failed to set up tracing: %v", err)
+	}
+	// Runs after the pool is closed, so the spans of drained requests are flushed
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("failed to flush spans", "error", err)
+		}
+	}()
+
+	if err := metrics.RegisterPool(pool); err != nil {
+		return fmt.Errorf("failed to register pool metrics: %v", err)
+	}
+
+	// Configured matching defaults; the configuration already holds the
+	// built-in values for every setting the file leaves out
+	matchOverrides := matcher.MatchOverrides{
+		Strategy:       cfg.Matching.Strategy,
+		TopN:           &cfg.Matching.TopN,
+		MaxDistance:    &cfg.Matching.MaxDistance,
+		CandidateLimit: &cfg.Matching.CandidateLimit,
+		MinScore:       &cfg.Matching.MinScore,
+		MinTfidfScore:  &cfg.Matching.MinTfidfScore,
+		EfSearch:       &cfg.VectorIndex.EfSearch,
+		Probes:         &cfg.VectorIndex.Probes,
+		MaxScanTuples:  &cfg.VectorIndex.MaxScanTuples,
+		Profile:        scoringProfile(cfg.Scoring),
+	}
+	matchDefaults := matcher.DefaultMatchOptions().Merge(matchOverrides)
+	if err := matchDefaults.Validate(); err != nil {
+		return fmt.Errorf("invalid matching configuration: %v", err)
+	}
+
+	clusterDefaults := matcher.ClusterOptions{
+		MinScore:   cfg.Clustering.MinScore,
+		MinDensity: cfg.Clustering.MinDensity,
+	}
+	if err := clusterDefaults.Validate(); err != nil {
+		return fmt.Errorf("invalid clustering configuration: %v", err)
+	}
+
+	survivorship := matcher.DefaultSurvivorshipRules()
+	if cfg.Survivorship.DefaultRule != "" {
+		survivorship.Default = cfg.Survivorship.DefaultRule
+	}
+	survivorship.Fields = cfg.Survivorship.Fields
+	survivorship.SourcePriority = cfg.Survivorship.SourcePriority
+	if err := survivorship.Validate(); err != nil {
+		return fmt.Errorf("invalid survivorship configuration: %v", err)
+	}
+
+	embedder := matcher.PythonEmbedder{
+		ScriptPath: cfg.Embedding.ScriptPath,
+		Python:     cfg.Embedding.Python,
+		Env:        cfg.DBCreds.PGEnv(),
+	}
+
+	auths := authenticators(cfg.Auth, pool)
+	if len(auths) == 0 && !cfg.Auth.Disabled {
+		return errors.New("no API authentication is configured; set auth.disabled to serve the API without it")
+	}
+
+	// Both APIs take requests from one token bucket per client
+	var limiter *api.RateLimiter
+	if cfg.Limits.RequestsPerSecond > 0 {
+		limiter = api.NewRateLimiter(cfg.Limits.RequestsPerSecond, cfg.Limits.Burst)
 	}
-	defer pool.Close()
-	fmt.Println("Database connection pool created successfully")
 
 	// Set up the HTTP server
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery())
+	api.SetupRoutes(router, pool, api.Options{
+		MatchDefaults:   matchDefaults,
+		ClusterDefaults: clusterDefaults,
+		Survivorship:    survivorship,
+		Auth:            auths,
+		Limits: api.Limits{
+			RequestsPerSecond:    cfg.Limits.RequestsPerSecond,
+			Burst:                cfg.Limits.Burst,
+			MaxConcurrentBatches: cfg.Limits.MaxConcurrentBatches,
+			MaxUploadBytes:       cfg.Limits.MaxUploadBytes,
+			MaxUploadRows:        cfg.Limits.MaxUploadRows,
+			Limiter:              limiter,
+		},
+		Pipeline: api.Pipeline{
+			LoadTable: cfg.DBCreds.LoadTable,
+			Workers:   cfg.Pipeline.Workers,
+			Embedder:  embedder,
+		},
+	})
+	server := api.NewServer(router, api.ServerOptions{
+		Addr:              cfg.Server.Addr,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		TLSCertFile:       cfg.Server.TLSCertFile,
+		TLSKeyFile:        cfg.Server.TLSKeyFile,
+	})
+
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("starting server", "addr", cfg.Server.Addr, "tls", server.TLS())
+		serveErr <- server.ListenAndServe()
+	}()
+
+	// The gRPC API runs beside the REST API and shares its matching defaults
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Addr != "" {
+		m, err := amp.New(amp.Options{
+			Pool:     pool,
+			Embedder: amp.PythonEmbedder(embedder),
+			Match: amp.MatchOverrides{
+				Strategy:       matchOverrides.Strategy,
+				TopN:           matchOverrides.TopN,
+				MaxDistance:    matchOverrides.MaxDistance,
+				CandidateLimit: matchOverrides.CandidateLimit,
+				MinScore:       matchOverrides.MinScore,
+				MinTfidfScore:  matchOverrides.MinTfidfScore,
+				EfSearch:       matchOverrides.EfSearch,
+				Probes:         matchOverrides.Probes,
+				MaxScanTuples:  matchOverrides.MaxScanTuples,
+				Profile:        (*amp.ScoringProfile)(matchOverrides.Profile),
+			},
+			Workers: cfg.Pipeline.Workers,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create matcher: %v", err)
+		}
+		lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %v", cfg.GRPC.Addr, err)
+		}
+		grpcServer, err = newGRPCServer(cfg, auths, limiter)
+		if err != nil {
+			return err
+		}
+		addressmatchv1.RegisterAddressMatchServer(grpcServer, grpcapi.NewServer(m, m.Defaults()))
+		go func() {
+			slog.Info("starting gRPC server", "addr", cfg.GRPC.Addr, "tls", cfg.Server.TLSCertFile != "")
+			if err := grpcServer.Serve(lis); err != nil {
+				serveErr <- fmt.Errorf("gRPC server failed: %v", err)
+			}
+		}()
+	}
 
-	api.SetupRoutes(router, pool)
+	// Serve until a signal arrives or a listener fails
+	var failure error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout)
+	case failure = <-serveErr:
+		slog.Error("server failed; shutting down", "error", failure)
+	}
 
-	fmt.Println("Starting server on :8080")
-	log.Fatal(router.Run(":8080"))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stopGRPC(shutdownCtx, grpcServer)
+		}()
+	}
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		slog.Warn("in-flight requests canceled at the shutdown deadline", "error", shutdownErr)
+	}
+	wg.Wait()
+	slog.Info("servers stopped")
+	return failure
 }
 
+// newGRPCServer returns a gRPC server that authenticates and rate limits calls
+// like the REST API, and serves TLS with the REST API's certificate when one
+// is configured
+func newGRPCServer(cfg *config.Config, auths []api.Authenticator, limiter *api.RateLimiter) (*grpc.Server, error) {
+	unary := []grpc.UnaryServerInterceptor{grpcapi.UnaryAuthInterceptor(auths...)}
+	stream := []grpc.StreamServerInterceptor{grpcapi.StreamAuthInterceptor(auths...)}
+	if limiter != nil {
+		unary = append(unary, grpcapi.UnaryRateLimitInterceptor(limiter))
+		stream = append(stream, grpcapi.StreamRateLimitInterceptor(limiter))
+	}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	if cfg.Server.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gRPC TLS credentials: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	return grpc.NewServer(opts...), nil
+}
+
+// stopGRPC waits for in-flight calls to finish, and cancels those still
+// running when ctx is done
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("in-flight gRPC calls canceled at the shutdown deadline")
+		s.Stop()
+		<-done
+	}
+}
+
+// scoringProfile returns the configured score weights, or nil for the
+// default profile when none is set
+func scoringProfile(cfg config.ScoringConfig) *matcher.ScoringProfile {
+	if !cfg.IsSet() {
+		return nil
+	}
+	return &matcher.ScoringProfile{
+		Similarity:  cfg.Similarity,
+		Tfidf:       cfg.Tfidf,
+		FirstName:   cfg.FirstName,
+		LastName:    cfg.LastName,
+		Street:      cfg.Street,
+		City:        cfg.City,
+		PhoneNumber: cfg.PhoneNumber,
+		ZipCode:     cfg.ZipCode,
+		BinKeyMatch: cfg.BinKeyMatch,
+	}
+}
+
+// authenticators builds the API authenticators enabled in the configuration
+func authenticators(cfg config.AuthConfig, pool *pgxpool.Pool) []api.Authenticator {
+	if cfg.Disabled {
+		return nil
+	}
+	var auths []api.Authenticator
+	if len(cfg.APIKeys) > 0 {
+		keys := api.StaticKeys{}
+		for _, k := range cfg.APIKeys {
+			keys[strings.ToLower(k.KeyHash)] = api.Principal{Subject: k.Subject, Scopes: k.Scopes, Tenant: k.Tenant}
+		}
+		auths = append(auths, api.APIKeyAuthenticator{Keys: keys})
+	}
+	if cfg.APIKeyTable {
+		auths = append(auths, api.APIKeyAuthenticator{Keys: api.DBKeys{Pool: pool}})
+	}
+	if cfg.JWT.Secret != "" {
+		auths = append(auths, api.JWTAuthenticator{Secret: []byte(cfg.JWT.Secret), Issuer: cfg.JWT.Issuer, Audience: cfg.JWT.Audience})
+	}
+	return auths
+}
@@ -0,0 +1,174 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+// Package logging configures the structured logger of the service. Records
+// carry the request and trace IDs of their context, and attributes holding
+// personal data are masked unless PII logging is enabled.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Redacted replaces the value of attributes holding personal data
+const Redacted = "[REDACTED]"
+
+// piiKeys are the attribute keys masked by the redaction layer. Keys with an
+// input_ or candidate_ prefix are masked as well.
+var piiKeys = map[string]bool{
+	"first_name":   true,
+	"last_name":    true,
+	"name":         true,
+	"phone_number": true,
+	"phone":        true,
+	"street":       true,
+	"address":      true,
+	// error_detail holds error text, which may quote record values
+	"error_detail": true,
+}
+
+// Config selects the log level, format and redaction
+type Config struct {
+	// Level is debug, info, warn or error
+	Level string
+	// Format is json or text
+	Format string
+	// LogPII disables the redaction of names, phones and streets. It is
+	// meant for local debugging only.
+	LogPII bool
+}
+
+// New returns a logger writing to w
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	var level slog.Level
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if !cfg.LogPII {
+		opts.ReplaceAttr = redact
+	}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Setup makes a logger writing to w the default, which the standard log
+// package writes through as well
+func Setup(w io.Writer, cfg Config) error {
+	logger, err := New(w, cfg)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// IsPII reports whether an attribute key holds personal data
+func IsPII(key string) bool {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "input_"), "candidate_")
+	return piiKeys[key]
+}
+
+// redact masks the values of PII attributes, including those nested in groups
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if IsPII(a.Key) && !(a.Value.Kind() == slog.KindString && a.Value.String() == "") {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// Map turns a map into a group value, so that its PII entries are redacted
+// like any other attribute
+func Map(m map[string]interface{}) slog.Value {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, m[k]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose log records carry the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request ID and trace ID of the record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
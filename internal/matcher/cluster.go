@@ -0,0 +1,342 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ClusterOptions controls how matched pairs are turned into entities
+type ClusterOptions struct {
+	// MinScore is the smallest pair score that links two records
+	MinScore float64
+	// MinDensity, when positive, splits clusters whose share of linked record
+	// pairs is below it by dropping their weakest links, which stops long
+	// chains of marginal matches from merging unrelated customers
+	MinDensity float64
+}
+
+// DefaultClusterOptions returns plain connected components over strong pairs
+func DefaultClusterOptions() ClusterOptions {
+	return ClusterOptions{MinScore: 70}
+}
+
+// Validate checks the clustering thresholds
+func (o ClusterOptions) Validate() error {
+	if o.MinScore < 0 || o.MinScore > 100 {
+		return fmt.Errorf("min_score must be in [0, 100], got %v", o.MinScore)
+	}
+	if o.MinDensity < 0 || o.MinDensity > 1 {
+		return fmt.Errorf("min_density must be in [0, 1], got %v", o.MinDensity)
+	}
+	return nil
+}
+
+// Cluster is one resolved entity. EntityID is the lowest member customer id.
+type Cluster struct {
+	EntityID int   `json:"entity_id"`
+	Members  []int `json:"members"`
+}
+
+type edge struct {
+	a, b  int
+	score float64
+}
+
+// unionFind is a disjoint set over customer ids with path compression
+type unionFind struct {
+	parent map[int]int
+	size   map[int]int
+}
+
+func newUnionFind(ids []int) *unionFind {
+	uf := &unionFind{parent: make(map[int]int, len(ids)), size: make(map[int]int, len(ids))}
+	for _, id := range ids {
+		uf.add(id)
+	}
+	return uf
+}
+
+func (uf *unionFind) add(id int) {
+	if _, ok := uf.parent[id]; !ok {
+		uf.parent[id] = id
+		uf.size[id] = 1
+	}
+}
+
+func (uf *unionFind) find(id int) int {
+	root := id
+	for uf.parent[root] != root {
+		root = uf.parent[root]
+	}
+	for uf.parent[id] != root {
+		next := uf.parent[id]
+		uf.parent[id] = root
+		id = next
+	}
+	return root
+}
+
+func (uf *unionFind) union(a, b int) {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra == rb {
+		return
+	}
+	if uf.size[ra] < uf.size[rb] {
+		ra, rb = rb, ra
+	}
+	uf.parent[rb] = ra
+	uf.size[ra] += uf.size[rb]
+}
+
+// ClusterRecords groups ids into entities linked by pairs scoring at least
+// opts.MinScore. Every id is assigned to exactly one cluster; ids without a
+// qualifying pair become singletons. Clusters are ordered by entity id.
+func ClusterRecords(ids []int, pairs []Candidate, opts ClusterOptions) []Cluster {
+	var edges []edge
+	for _, p := range pairs {
+		if p.InputCustomerID == p.CandidateCustomerID || p.Score < opts.MinScore {
+			continue
+		}
+		edges = append(edges, edge{a: p.InputCustomerID, b: p.CandidateCustomerID, score: p.Score})
+	}
+
+	all := append([]int(nil), ids...)
+	for _, e := range edges {
+		all = append(all, e.a, e.b)
+	}
+
+	var clusters []Cluster
+	for _, component := range components(all, edges) {
+		if opts.MinDensity > 0 {
+			clusters = append(clusters, splitSparse(component.members, component.edges, opts.MinDensity)...)
+		} else {
+			clusters = append(clusters, newCluster(component.members))
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].EntityID < clusters[j].EntityID
+	})
+	return clusters
+}
+
+type component struct {
+	members []int
+	edges   []edge
+}
+
+// components returns the connected components of the graph
+func components(ids []int, edges []edge) []component {
+	uf := newUnionFind(ids)
+	for _, e := range edges {
+		uf.union(e.a, e.b)
+	}
+
+	byRoot := make(map[int]*component)
+	var roots []int
+	for id := range uf.parent {
+		root := uf.find(id)
+		c, ok := byRoot[root]
+		if !ok {
+			c = &component{}
+			byRoot[root] = c
+			roots = append(roots, root)
+		}
+		c.members = append(c.members, id)
+	}
+	for _, e := range edges {
+		c := byRoot[uf.find(e.a)]
+		c.edges = append(c.edges, e)
+	}
+
+	result := make([]component, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, *byRoot[root])
+	}
+	return result
+}
+
+// splitSparse drops the weakest link of a component until every remaining
+// component reaches the minimum edge density
+func splitSparse(members []int, edges []edge, minDensity float64) []Cluster {
+	n := len(members)
+	if n <= 2 || density(n, len(edges)) >= minDensity {
+		return []Cluster{newCluster(members)}
+	}
+
+	weakest := 0
+	for i, e := range edges {
+		if e.score < edges[weakest].score {
+			weakest = i
+		}
+	}
+	remaining := append(append([]edge(nil), edges[:weakest]...), edges[weakest+1:]...)
+
+	var clusters []Cluster
+	for _, c := range components(members, remaining) {
+		clusters = append(clusters, splitSparse(c.members, c.edges, minDensity)...)
+	}
+	return clusters
+}
+
+// density is the share of possible record pairs that are linked
+func density(nodes int, edges int) float64 {
+	if nodes < 2 {
+		return 1
+	}
+	return float64(2*edges) / float64(nodes*(nodes-1))
+}
+
+func newCluster(members []int) Cluster {
+	sorted := append([]int(nil), members...)
+	sort.Ints(sorted)
+	return Cluster{EntityID: sorted[0], Members: sorted}
+}
+
+// EntityResolution summarises a stored clustering run
+type EntityResolution struct {
+	RunID    int `json:"run_id"`
+	Records  int `json:"records"`
+	Entities int `json:"entities"`
+}
+
+// ResolveEntities clusters the whole candidate space into entities and stores
+// the assignments in entity_clusters under a new run
+func ResolveEntities(ctx context.Context, pool *pgxpool.Pool, opts MatchOptions, copts ClusterOptions) (EntityResolution, error) {
+	if err := copts.Validate(); err != nil {
+		return EntityResolution{}, err
+	}
+
+	// Only pairs strong enough to link records are needed
+	opts.MinScore = copts.MinScore
+
+	var pairs []Candidate
+	req := DuplicateRequest{PageSize: maxDuplicatePageSize}
+	for {
+		page, err := FindDuplicates(ctx, pool, req, opts)
+		if err != nil {
+			return EntityResolution{}, err
+		}
+		pairs = append(pairs, page.Pairs...)
+		if !page.HasMore {
+			break
+		}
+		req.After = page.NextAfter
+	}
+
+	rows, err := pool.Query(ctx, "SELECT customer_id FROM customer_matching WHERE run_id = 0 AND tenant_id = $1", TenantFromContext(ctx))
+	if err != nil {
+		return EntityResolution{}, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return EntityResolution{}, err
+	}
+
+	clusters := ClusterRecords(ids, pairs, copts)
+
+	// The run is created in the transaction that stores its clusters, so a
+	// failed copy leaves no empty run behind
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return EntityResolution{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	runID, err := CreateNewRun(ctx, tx, "Entity Resolution")
+	if err != nil {
+		return EntityResolution{}, err
+	}
+	var assignments [][]interface{}
+	for _, c := range clusters {
+		for _, member := range c.Members {
+			assignments = append(assignments, []interface{}{runID, member, c.EntityID})
+		}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"entity_clusters"}, []string{"run_id", "customer_id", "entity_id"}, pgx.CopyFromRows(assignments)); err != nil {
+		return EntityResolution{}, fmt.Errorf("failed to store entity clusters: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return EntityResolution{}, err
+	}
+
+	slog.InfoContext(ctx, "resolved entities", "run_id", runID, "records", len(assignments), "entities", len(clusters))
+	return EntityResolution{RunID: runID, Records: len(assignments), Entities: len(clusters)}, nil
+}
+
+// EntityMembers is the cluster a record belongs to in an entity resolution run
+type EntityMembers struct {
+	RunID    int           `json:"run_id"`
+	EntityID int           `json:"entity_id"`
+	Members  []InputRecord `json:"members"`
+}
+
+// LoadEntityMembers returns every record sharing the customer's entity. A runID
+// of 0 selects the tenant's latest entity resolution run.
+func LoadEntityMembers(ctx context.Context, pool *pgxpool.Pool, runID int, customerID int) (EntityMembers, error) {
+	if runID == 0 {
+		if err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(ec.run_id), 0) FROM entity_clusters ec JOIN runs r ON (r.run_id = ec.run_id) WHERE r.tenant_id = $1", TenantFromContext(ctx)).Scan(&runID); err != nil {
+			return EntityMembers{}, err
+		}
+	}
+	if err := checkRunTenant(ctx, pool, runID); err != nil {
+		return EntityMembers{}, err
+	}
+
+	result := EntityMembers{RunID: runID}
+	err := pool.QueryRow(ctx, "SELECT entity_id FROM entity_clusters WHERE run_id = $1 AND customer_id = $2", runID, customerID).Scan(&result.EntityID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return EntityMembers{}, fmt.Errorf("customer %d in run %d: %w", customerID, runID, ErrNotFound)
+	}
+	if err != nil {
+		return EntityMembers{}, err
+	}
+
+	rows, err := pool.Query(ctx,
+		`SELECT cm.customer_id, cm.run_id, COALESCE(cm.first_name, ''), COALESCE(cm.last_name, ''), COALESCE(cm.street, ''),
+		        COALESCE(cm.city, ''), COALESCE(cm.state, ''), COALESCE(cm.zip_code, ''), COALESCE(cm.phone_number, '')
+		 FROM entity_clusters ec
+		 JOIN customer_matching cm ON (cm.customer_id = ec.customer_id AND cm.run_id = 0 AND cm.tenant_id = $3)
+		 WHERE ec.run_id = $1 AND ec.entity_id = $2
+		 ORDER BY cm.customer_id`, runID, result.EntityID, TenantFromContext(ctx))
+	if err != nil {
+		return EntityMembers{}, err
+	}
+	result.Members, err = pgx.CollectRows(rows, scanInputRecord)
+	return result, err
+}
@@ -0,0 +1,159 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// duplicatesSQL pairs a page of run 0 records with their nearest run 0
+// records of a higher customer id, so each unordered pair is produced once
+// and a record never pairs with itself
+var duplicatesSQL = renderVectorQuery(vectorQueryFilters{
+	Input: "input.run_id = 0 AND input.customer_id = ANY($1)",
+	Candidates: []string{
+		"candidate_vec.customer_id > input.customer_id",
+		"($5::TEXT = '' OR blocked.state = $5)",
+		"($6::TEXT = '' OR blocked.zip_code = $6)",
+	},
+})
+
+const (
+	duplicatesStatement = "duplicates_v3"
+
+	defaultDuplicatePageSize = 100
+	maxDuplicatePageSize     = 1000
+)
+
+// DuplicateRequest asks for duplicate pairs within the candidate space (run 0).
+// Records are paged by customer id: each page covers up to PageSize records
+// with a customer id greater than After.
+type DuplicateRequest struct {
+	MaxDistance    *float64 `json:"max_distance,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+	MinScore       *float64 `json:"min_score,omitempty"`
+	State          string   `json:"state"`
+	ZipCode        string   `json:"zip_code"`
+	PageSize       int      `json:"page_size"`
+	After          int      `json:"after"`
+}
+
+// DuplicatePage holds the unique pairs found for one page of records. Pass
+// NextAfter as After to fetch the next page while HasMore is true.
+type DuplicatePage struct {
+	Pairs     []Candidate `json:"pairs"`
+	NextAfter int         `json:"next_after"`
+	HasMore   bool        `json:"has_more"`
+}
+
+// Options merges the request's thresholds over the given defaults
+func (r DuplicateRequest) Options(defaults MatchOptions) MatchOptions {
+	return defaults.Merge(MatchOverrides{
+		MaxDistance:    r.MaxDistance,
+		CandidateLimit: r.CandidateLimit,
+		MinScore:       r.MinScore,
+	})
+}
+
+// Validate checks the paging parameters
+func (r DuplicateRequest) Validate() error {
+	if r.PageSize < 0 || r.PageSize > maxDuplicatePageSize {
+		return fmt.Errorf("page_size must be between 0, for the default, and %d, got %d", maxDuplicatePageSize, r.PageSize)
+	}
+	if r.After < 0 {
+		return fmt.Errorf("after must not be negative, got %d", r.After)
+	}
+	return nil
+}
+
+// FindDuplicates returns unique, unordered pairs of run 0 records scoring at
+// least opts.MinScore. Self-pairs are never returned and each pair appears once,
+// with the lower customer id on the input side.
+func FindDuplicates(ctx context.Context, pool *pgxpool.Pool, req DuplicateRequest, opts MatchOptions) (DuplicatePage, error) {
+	if err := req.Validate(); err != nil {
+		return DuplicatePage{}, err
+	}
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = defaultDuplicatePageSize
+	}
+
+	tenant := TenantFromContext(ctx)
+	rows, err := pool.Query(ctx,
+		`SELECT customer_id FROM customer_matching
+		 WHERE run_id = 0 AND tenant_id = $5 AND customer_id > $1
+		 AND ($2::TEXT = '' OR state = $2)
+		 AND ($3::TEXT = '' OR zip_code = $3)
+		 ORDER BY customer_id
+		 LIMIT $4`,
+		req.After, req.State, req.ZipCode, pageSize, tenant)
+	if err != nil {
+		return DuplicatePage{}, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return DuplicatePage{}, err
+	}
+
+	page := DuplicatePage{Pairs: []Candidate{}, NextAfter: req.After}
+	if len(ids) == 0 {
+		return page, nil
+	}
+	page.NextAfter = ids[len(ids)-1]
+	page.HasMore = len(ids) == pageSize
+
+	args := []interface{}{ids, opts.MaxDistance, opts.CandidateLimit, tenant, req.State, req.ZipCode}
+	profile := opts.scoringProfile()
+	keep := func(c *Candidate) bool {
+		scoreCandidate(c, profile)
+		return c.Score >= opts.MinScore
+	}
+	pairs, err := queryCandidates(ctx, pool, duplicatesStatement, duplicatesSQL, args, opts, keep)
+	if err != nil {
+		return DuplicatePage{}, err
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].InputCustomerID != pairs[j].InputCustomerID {
+			return pairs[i].InputCustomerID < pairs[j].InputCustomerID
+		}
+		return pairs[i].Score > pairs[j].Score
+	})
+	page.Pairs = append(page.Pairs, pairs...)
+
+	slog.InfoContext(ctx, "found duplicate pairs", "pairs", len(pairs), "from_customer_id", ids[0], "to_customer_id", page.NextAfter)
+	return page, nil
+}
@@ -0,0 +1,101 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Pipeline stages reported by StageError and the stage metrics
+const (
+	StageCreateRun         = "create_run"
+	StageLoadInput         = "load_input"
+	StageReferenceEntities = "reference_entities"
+	StageStandardization   = "standardization"
+	StageBinaryKeys        = "binary_keys"
+	StageTFIDF             = "tfidf"
+	StageEmbeddings        = "embeddings"
+	StageMatch             = "match"
+	StageStoreResults      = "store_results"
+)
+
+var (
+	// ErrInvalidRequest is matched by errors that reject a request's input
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrNotFound is returned when a run or record does not exist for the
+	// tenant of the context
+	ErrNotFound = errors.New("not found")
+)
+
+// ValidationError rejects a request the matcher cannot act on. It matches
+// ErrInvalidRequest.
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
+
+// invalidf returns a ValidationError with a formatted message
+func invalidf(format string, args ...interface{}) error {
+	return &ValidationError{Msg: fmt.Sprintf(format, args...)}
+}
+
+// StageError reports the pipeline stage a failure happened in
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s stage failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// stageError wraps err in a StageError unless it already carries a stage
+func stageError(stage string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *StageError
+	if errors.As(err, &se) {
+		return err
+	}
+	return &StageError{Stage: stage, Err: err}
+}
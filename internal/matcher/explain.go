@@ -0,0 +1,146 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Explanation shows how a candidate was found and how its score was built
+type Explanation struct {
+	Strategy string `json:"strategy"`
+	// Blocking lists the conditions that put the pair in the candidate set
+	Blocking []string              `json:"blocking"`
+	Features []FeatureContribution `json:"features"`
+	// RawScore is the weighted sum before it is scaled and clamped to [1, 100]
+	RawScore  float64            `json:"raw_score"`
+	Input     StandardizedRecord `json:"input"`
+	Candidate StandardizedRecord `json:"candidate"`
+}
+
+// FeatureContribution is one term of the composite score
+type FeatureContribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// StandardizedRecord holds the standardized forms of a pair's fields, for
+// display only; scoring does not read it
+type StandardizedRecord struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Street      string `json:"street"`
+	Unit        string `json:"unit"`
+	City        string `json:"city"`
+	State       string `json:"state"`
+	ZipCode     string `json:"zip_code"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// scoreFeatures returns every weighted feature of a candidate under the
+// profile. Contributions are on the 0-100 scale of the final score.
+func scoreFeatures(candidate *Candidate, profile ScoringProfile) []FeatureContribution {
+	binKeyMatch := 0.0
+	if candidate.BinKeyMatch {
+		binKeyMatch = 1.0
+	}
+
+	features := []FeatureContribution{
+		{Feature: "vector_similarity", Value: 1 - candidate.Similarity, Weight: profile.Similarity},
+		{Feature: "tfidf_score", Value: candidate.TfidfScore, Weight: profile.Tfidf},
+		{Feature: "first_name", Value: candidate.TrigramCosineFirstName, Weight: profile.FirstName},
+		{Feature: "last_name", Value: candidate.TrigramCosineLastName, Weight: profile.LastName},
+		{Feature: "street", Value: candidate.TrigramCosineStreet, Weight: profile.Street},
+		{Feature: "city", Value: candidate.TrigramCosineCity, Weight: profile.City},
+		{Feature: "phone_number", Value: candidate.TrigramCosinePhoneNumber, Weight: profile.PhoneNumber},
+		{Feature: "zip_code", Value: candidate.TrigramCosineZipCode, Weight: profile.ZipCode},
+		{Feature: "bin_key_match", Value: binKeyMatch, Weight: profile.BinKeyMatch},
+	}
+	for i := range features {
+		features[i].Contribution = features[i].Value * features[i].Weight * 100
+	}
+	return features
+}
+
+// compositeScore combines the features under the profile's weights into a score in [1, 100]
+func compositeScore(candidate *Candidate, profile ScoringProfile) float64 {
+	return math.Max(1, math.Min(100, rawScore(scoreFeatures(candidate, profile))))
+}
+
+func rawScore(features []FeatureContribution) float64 {
+	sum := 0.0
+	for _, f := range features {
+		sum += f.Contribution
+	}
+	return sum
+}
+
+// ExplainCandidate attaches an explanation of the candidate's score under the
+// given strategy and profile
+func ExplainCandidate(candidate *Candidate, strategy Strategy, opts MatchOptions, profile ScoringProfile) {
+	features := scoreFeatures(candidate, profile)
+	explanation := &Explanation{
+		Strategy: strategy.Name,
+		Blocking: []string{},
+		Features: features,
+		RawScore: rawScore(features) / 100,
+		Input: standardizeRecord(candidate.InputFirstName, candidate.InputLastName, candidate.InputStreet,
+			candidate.InputCity, candidate.InputState, candidate.InputZipCode, candidate.InputPhoneNumber),
+		Candidate: standardizeRecord(candidate.CandidateFirstName, candidate.CandidateLastName, candidate.CandidateStreet,
+			candidate.CandidateCity, candidate.CandidateState, candidate.CandidateZipCode, candidate.CandidatePhoneNumber),
+	}
+	if strategy.Blocking != nil {
+		explanation.Blocking = append(explanation.Blocking, strategy.Blocking(*candidate, opts)...)
+	}
+	candidate.Explanation = explanation
+}
+
+func standardizeRecord(firstName, lastName, street, city, state, zipCode, phoneNumber string) StandardizedRecord {
+	base, unit := SplitUnit(street)
+	return StandardizedRecord{
+		FirstName: strings.ToLower(strings.TrimSpace(firstName)),
+		LastName:  strings.ToLower(strings.TrimSpace(lastName)),
+		Street:    base,
+		Unit:      unit,
+		City:      strings.ToLower(strings.TrimSpace(city)),
+		State:     strings.ToLower(strings.TrimSpace(state)),
+		ZipCode:   strings.TrimSpace(zipCode),
+		PhoneNumber: strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, phoneNumber),
+	}
+}
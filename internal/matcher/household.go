@@ -0,0 +1,203 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed household.sql
+var householdSQL string
+
+const (
+	householdStatement = "households_v3"
+
+	// DefaultHouseholdMinScore is the address score two records need to share a household
+	DefaultHouseholdMinScore = 80.0
+
+	// householdSurnameThreshold is the last name similarity required when surnames must agree
+	householdSurnameThreshold = 0.8
+)
+
+// unitPattern finds secondary unit designators and their values, e.g. "apt 2b", "# 3c", "ste 200"
+var unitPattern = regexp.MustCompile(`(?:\b(?:apt|apartment|unit|ste|suite|rm|room|bldg|building|fl|floor|lot)\b\.?|#)\s*#?\s*([a-z0-9][a-z0-9-]*)`)
+
+// SplitUnit separates a street address into its standardized base address
+// and a normalized unit, so "12 Main St Apt 02-B" gives ("12 main st", "2b").
+// Several designators (building, floor, apartment) are joined by a space.
+func SplitUnit(street string) (string, string) {
+	street = strings.ToLower(street)
+
+	var units []string
+	for _, m := range unitPattern.FindAllStringSubmatch(street, -1) {
+		unit := strings.ReplaceAll(m[1], "-", "")
+		if trimmed := strings.TrimLeft(unit, "0"); trimmed != "" {
+			unit = trimmed
+		}
+		units = append(units, unit)
+	}
+
+	base, _ := StandardizeAddress(unitPattern.ReplaceAllString(street, " "))
+	return base, strings.Join(units, " ")
+}
+
+// HouseholdScoringProfile weights only the address features
+func HouseholdScoringProfile() ScoringProfile {
+	return ScoringProfile{
+		Street:      0.45,
+		City:        0.15,
+		ZipCode:     0.2,
+		BinKeyMatch: 0.2,
+	}
+}
+
+// ScoreHousehold scores a pair on the address alone, comparing streets without
+// their units. It returns false when the units differ, as two apartments in one
+// building are separate households, or when matchSurname is set and the last
+// names disagree. A record without a unit never pairs with one that has a unit,
+// so it cannot chain two apartments into one household.
+func ScoreHousehold(candidate *Candidate, matchSurname bool) bool {
+	inputBase, inputUnit := SplitUnit(candidate.InputStreet)
+	candidateBase, candidateUnit := SplitUnit(candidate.CandidateStreet)
+	if inputUnit != candidateUnit {
+		return false
+	}
+
+	candidate.TrigramCosineLastName = ngramFrequencySimilarity(candidate.InputLastName, candidate.CandidateLastName, 2)
+	if matchSurname && candidate.TrigramCosineLastName < householdSurnameThreshold {
+		return false
+	}
+
+	candidate.TrigramCosineStreet = ngramFrequencySimilarity(inputBase, candidateBase, 2)
+	candidate.TrigramCosineCity = ngramFrequencySimilarity(candidate.InputCity, candidate.CandidateCity, 2)
+	candidate.TrigramCosineZipCode = ngramFrequencySimilarity(candidate.InputZipCode, candidate.CandidateZipCode, 2)
+	candidate.Score = compositeScore(candidate, HouseholdScoringProfile())
+	return true
+}
+
+// HouseholdRequest asks for the households within the candidate space (run 0),
+// optionally scoped to a state or zip code
+type HouseholdRequest struct {
+	MinScore       *float64 `json:"min_score,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+	MatchSurname   bool     `json:"match_surname"`
+	State          string   `json:"state"`
+	ZipCode        string   `json:"zip_code"`
+	// IncludeSingles also returns single-member households
+	IncludeSingles bool `json:"include_singles"`
+}
+
+// Options merges the request's thresholds over the given defaults. The
+// household score threshold replaces the person threshold when unset.
+func (r HouseholdRequest) Options(defaults MatchOptions) MatchOptions {
+	opts := defaults.Merge(MatchOverrides{CandidateLimit: r.CandidateLimit})
+	opts.MinScore = DefaultHouseholdMinScore
+	if r.MinScore != nil {
+		opts.MinScore = *r.MinScore
+	}
+	return opts
+}
+
+// Household is a group of records at the same address and unit
+type Household struct {
+	HouseholdID int           `json:"household_id"`
+	Street      string        `json:"street"`
+	Unit        string        `json:"unit"`
+	City        string        `json:"city"`
+	ZipCode     string        `json:"zip_code"`
+	Members     []InputRecord `json:"members"`
+}
+
+// FindHouseholds groups the run 0 records in scope into households. The
+// household id is the lowest member customer id.
+func FindHouseholds(ctx context.Context, pool *pgxpool.Pool, req HouseholdRequest, opts MatchOptions) ([]Household, error) {
+	tenant := TenantFromContext(ctx)
+	rows, err := pool.Query(ctx,
+		`SELECT customer_id, run_id, COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(street, ''),
+		        COALESCE(city, ''), COALESCE(state, ''), COALESCE(zip_code, ''), COALESCE(phone_number, '')
+		 FROM customer_matching
+		 WHERE run_id = 0 AND tenant_id = $3
+		 AND ($1::TEXT = '' OR state = $1)
+		 AND ($2::TEXT = '' OR zip_code = $2)
+		 ORDER BY customer_id`,
+		req.State, req.ZipCode, tenant)
+	if err != nil {
+		return nil, err
+	}
+	records, err := pgx.CollectRows(rows, scanInputRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := func(c *Candidate) bool { return ScoreHousehold(c, req.MatchSurname) && c.Score >= opts.MinScore }
+	args := []interface{}{req.State, req.ZipCode, opts.CandidateLimit, tenant}
+	pairs, err := queryCandidates(ctx, pool, householdStatement, householdSQL, args, opts, keep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find household pairs: %v", err)
+	}
+
+	byID := make(map[int]InputRecord, len(records))
+	ids := make([]int, 0, len(records))
+	for _, r := range records {
+		byID[r.CustomerID] = r
+		ids = append(ids, r.CustomerID)
+	}
+
+	households := []Household{}
+	for _, cluster := range ClusterRecords(ids, pairs, ClusterOptions{MinScore: opts.MinScore}) {
+		if len(cluster.Members) < 2 && !req.IncludeSingles {
+			continue
+		}
+		head := byID[cluster.EntityID]
+		street, unit := SplitUnit(head.Street)
+		household := Household{
+			HouseholdID: cluster.EntityID,
+			Street:      street,
+			Unit:        unit,
+			City:        head.City,
+			ZipCode:     head.ZipCode,
+		}
+		for _, id := range cluster.Members {
+			household.Members = append(household.Members, byID[id])
+		}
+		households = append(households, household)
+	}
+
+	slog.InfoContext(ctx, "found households", "households", len(households), "pairs", len(pairs), "records", len(records))
+	return households, nil
+}
@@ -0,0 +1,102 @@
+WITH scope AS (
+    SELECT 
+        customer_id,
+        run_id,
+        first_name,
+        last_name,
+        street,
+        city,
+        state,
+        zip_code,
+        phone_number,
+        split_part(lower(trim(street)), ' ', 1) AS house_number,
+        -- Keys that rank candidates before the cap; unit is the first unit
+        -- SplitUnit finds
+        regexp_replace(lower(trim(street)), '\s+', ' ', 'g') AS street_key,
+        COALESCE(ltrim(replace(substring(lower(street) FROM '(?:\m(?:apt|apartment|unit|ste|suite|rm|room|bldg|building|fl|floor|lot)\M\.?|#)\s*#?\s*([a-z0-9][a-z0-9-]*)'), '-', ''), '0'), '') AS unit,
+        lower(trim(COALESCE(last_name, ''))) AS surname,
+        tenant_id
+    FROM customer_matching
+    WHERE run_id = 0
+    AND tenant_id = $4
+    AND ($1::TEXT = '' OR state = $1)
+    AND ($2::TEXT = '' OR zip_code = $2)
+),
+matches AS (
+    SELECT 
+        input.customer_id AS input_customer_id,
+        input.run_id AS input_run_id,
+        input.first_name AS input_first_name,
+        input.last_name AS input_last_name,
+        input.street AS input_street,
+        input.city AS input_city,
+        input.state AS input_state,
+        input.zip_code AS input_zip_code,
+        input.phone_number AS input_phone_number,
+        candidates.customer_id AS candidate_customer_id,
+        candidates.run_id AS candidate_run_id,
+        candidates.first_name AS candidate_first_name,
+        candidates.last_name AS candidate_last_name,
+        candidates.street AS candidate_street,
+        candidates.city AS candidate_city,
+        candidates.state AS candidate_state,
+        candidates.zip_code AS candidate_zip_code,
+        candidates.phone_number AS candidate_phone_number,
+        -- The cap keeps the likeliest co-residents: same unit, then same
+        -- street, then same surname
+        ROW_NUMBER() OVER (
+            PARTITION BY input.customer_id
+            ORDER BY (candidates.unit = input.unit) DESC,
+                     (candidates.street_key = input.street_key) DESC,
+                     (candidates.surname = input.surname) DESC,
+                     candidates.customer_id
+        ) AS rank,
+        input.tenant_id AS input_tenant_id,
+        candidates.tenant_id AS candidate_tenant_id
+    FROM scope input
+    -- Households are blocked on zip code and house number only; names play no
+    -- part. A higher candidate customer_id yields each unordered pair once.
+    JOIN scope candidates
+        ON (candidates.zip_code = input.zip_code
+            AND candidates.house_number = input.house_number
+            AND candidates.customer_id > input.customer_id)
+)
+SELECT 
+    matches.input_customer_id,
+    matches.input_run_id,
+    COALESCE(matches.input_first_name, '') AS input_first_name,
+    COALESCE(matches.input_last_name, '') AS input_last_name,
+    COALESCE(matches.input_street, '') AS input_street,
+    COALESCE(matches.input_city, '') AS input_city,
+    COALESCE(matches.input_state, '') AS input_state,
+    COALESCE(matches.input_zip_code, '') AS input_zip_code,
+    COALESCE(matches.input_phone_number, '') AS input_phone_number,
+    matches.candidate_customer_id,
+    matches.candidate_run_id,
+    COALESCE(matches.candidate_first_name, '') AS candidate_first_name,
+    COALESCE(matches.candidate_last_name, '') AS candidate_last_name,
+    COALESCE(matches.candidate_street, '') AS candidate_street,
+    COALESCE(matches.candidate_city, '') AS candidate_city,
+    COALESCE(matches.candidate_state, '') AS candidate_state,
+    COALESCE(matches.candidate_zip_code, '') AS candidate_zip_code,
+    COALESCE(matches.candidate_phone_number, '') AS candidate_phone_number,
+    0::FLOAT8 AS similarity,
+    EXISTS (
+        SELECT 1
+        FROM customer_keys input_key
+        JOIN customer_keys candidate_key
+            ON (candidate_key.binary_key = input_key.binary_key
+                AND candidate_key.tenant_id = matches.candidate_tenant_id
+                AND candidate_key.run_id = 0
+                AND candidate_key.customer_id = matches.candidate_customer_id)
+        WHERE input_key.tenant_id = matches.input_tenant_id
+        AND input_key.run_id = 0
+        AND input_key.customer_id = matches.input_customer_id
+    ) AS bin_key_match,
+    0::FLOAT8 AS tfidf_score,
+    matches.rank,
+    matches.input_tenant_id,
+    matches.candidate_tenant_id
+FROM matches
+WHERE matches.rank <= $3
+ORDER BY matches.input_customer_id, matches.rank;
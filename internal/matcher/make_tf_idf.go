@@ -31,7 +31,8 @@ package matcher
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"math"
 	"sort"
 	"strings"
@@ -41,12 +42,12 @@ import (
 )
 
 // Standardize the street address
-func standardizeStreet(street string) string {
+func standardizeStreet(street string) (string, error) {
 	standardizedStreet, err := StandardizeAddress(street)
 	if err != nil {
-		log.Fatalf("Error standardizing street: %v", err)
+		return "", fmt.Errorf("error standardizing street %q: %v", street, err)
 	}
-	return standardizedStreet
+	return standardizedStreet, nil
 }
 
 // Generate trigrams (3-grams) from a given text
@@ -72,10 +73,11 @@ func calculateIDF(totalDocs int, docFreq map[string]int) map[string]float64 {
 }
 
 // Generate candidate IDF and insert into tokens_idf
-func generateCandidateIDF(pool *pgxpool.Pool, runID int) map[string]float64 {
-	rows, err := pool.Query(context.Background(), "SELECT customer_id, lower(first_name) || ' ' || lower(last_name) as name, lower(street) as street FROM customer_matching WHERE run_id = 0")
+func generateCandidateIDF(ctx context.Context, pool *pgxpool.Pool, runID int) (map[string]float64, error) {
+	tenant := TenantFromContext(ctx)
+	rows, err := pool.Query(ctx, "SELECT customer_id, COALESCE(lower(first_name), '') || ' ' || COALESCE(lower(last_name), '') as name, COALESCE(lower(street), '') as street FROM customer_matching WHERE run_id = 0 AND tenant_id = $1", tenant)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -89,14 +91,16 @@ func generateCandidateIDF(pool *pgxpool.Pool, runID int) map[string]float64 {
 	for rows.Next() {
 		var customer Customer
 		if err := rows.Scan(&customer.ID, &customer.Name, &customer.Street); err != nil {
-			log.Fatal(err)
+			return nil, err
+		}
+		if customer.Street, err = standardizeStreet(customer.Street); err != nil {
+			return nil, err
 		}
-		customer.Street = standardizeStreet(customer.Street)
 		customers = append(customers, customer)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	totalDocs := len(customers)
@@ -141,42 +145,51 @@ func generateCandidateIDF(pool *pgxpool.Pool, runID int) map[string]float64 {
 
 	idf := calculateIDF(totalDocs, docFreq)
 
-	ctx := context.Background()
 	tx, err := pool.Begin(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	insertTokensIDF := "INSERT INTO tokens_idf (entity_type_id, ngram_token, ngram_idf, run_id) VALUES ($1, $2, $3, $4)"
+	insertTokensIDF := "INSERT INTO tokens_idf (entity_type_id, ngram_token, ngram_idf, run_id, tenant_id) VALUES ($1, $2, $3, $4, $5)"
 	for token, idfValue := range idf {
 		entityTypeID := 1 // Default to street entity type
 		if strings.Contains(token, " ") {
 			entityTypeID = 2 // Name entity type
 		}
-		_, err := tx.Exec(ctx, insertTokensIDF, entityTypeID, token, idfValue, runID)
+		_, err := tx.Exec(ctx, insertTokensIDF, entityTypeID, token, idfValue, runID, tenant)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	return idf
+	return idf, nil
+}
+
+// GenerateTFIDF generates TF/IDF vectors and inserts them into the database
+func GenerateTFIDF(ctx context.Context, pool *pgxpool.Pool, runID int) error {
+	ctx, st := startStage(ctx, StageTFIDF, runID)
+	err := generateTFIDF(ctx, pool, runID)
+	st.end(err)
+	return stageError(StageTFIDF, err)
 }
 
-// Generate TF/IDF vectors and insert them into the database
-func GenerateTFIDF(pool *pgxpool.Pool, runID int) {
+func generateTFIDF(ctx context.Context, pool *pgxpool.Pool, runID int) error {
 	// Ensure IDF values are generated and stored
-	generateCandidateIDF(pool, runID)
+	if _, err := generateCandidateIDF(ctx, pool, runID); err != nil {
+		return err
+	}
 
 	// Fetch IDF values from the database
+	tenant := TenantFromContext(ctx)
 	idf := make(map[string]float64)
-	rows, err := pool.Query(context.Background(), "SELECT ngram_token, ngram_idf FROM tokens_idf WHERE run_id = $1", runID)
+	rows, err := pool.Query(ctx, "SELECT ngram_token, ngram_idf FROM tokens_idf WHERE run_id = $1 AND tenant_id = $2", runID, tenant)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer rows.Close()
 
@@ -184,18 +197,18 @@ func GenerateTFIDF(pool *pgxpool.Pool, runID int) {
 		var token string
 		var idfValue float64
 		if err := rows.Scan(&token, &idfValue); err != nil {
-			log.Fatal(err)
+			return err
 		}
 		idf[token] = idfValue
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	rows, err = pool.Query(context.Background(), "SELECT customer_id, lower(first_name) || ' ' || lower(last_name) as name, lower(street) as street FROM customer_matching WHERE run_id = $1", runID)
+	rows, err = pool.Query(ctx, "SELECT customer_id, COALESCE(lower(first_name), '') || ' ' || COALESCE(lower(last_name), '') as name, COALESCE(lower(street), '') as street FROM customer_matching WHERE run_id = $1 AND tenant_id = $2", runID, tenant)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer rows.Close()
 
@@ -209,15 +222,18 @@ func GenerateTFIDF(pool *pgxpool.Pool, runID int) {
 	for rows.Next() {
 		var customer Customer
 		if err := rows.Scan(&customer.ID, &customer.Name, &customer.Street); err != nil {
-			log.Fatal(err)
+			return err
+		}
+		if customer.Street, err = standardizeStreet(customer.Street); err != nil {
+			return err
 		}
-		customer.Street = standardizeStreet(customer.Street)
 		customers = append(customers, customer)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Fatal(err)
+		return err
 	}
+	addRows(ctx, StageTFIDF, len(customers))
 
 	customerTokens := make([]struct {
 		CustomerID int
@@ -292,38 +308,38 @@ func GenerateTFIDF(pool *pgxpool.Pool, runID int) {
 		return customerTokens[i].CustomerID < customerTokens[j].CustomerID
 	})
 
-	ctx := context.Background()
 	tx, err := pool.Begin(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer tx.Rollback(ctx)
+	defer func() { tx.Rollback(ctx) }()
 
-	insertCustomerTokens := "INSERT INTO customer_tokens (customer_id, entity_type_id, ngram_token, ngram_tfidf, run_id) VALUES ($1, $2, $3, $4, $5)"
+	insertCustomerTokens := "INSERT INTO customer_tokens (customer_id, entity_type_id, ngram_token, ngram_tfidf, run_id, tenant_id) VALUES ($1, $2, $3, $4, $5, $6)"
 	tokenCount := 0
 	batchSize := 1000
 
 	for _, ct := range customerTokens {
-		_, err := tx.Exec(ctx, insertCustomerTokens, ct.CustomerID, ct.EntityType, ct.Token, ct.TfIdf, runID)
+		_, err := tx.Exec(ctx, insertCustomerTokens, ct.CustomerID, ct.EntityType, ct.Token, ct.TfIdf, runID, tenant)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		tokenCount++
 		if tokenCount%batchSize == 0 {
 			if err := tx.Commit(ctx); err != nil {
-				log.Fatal(err)
+				return err
 			}
 			tx, err = pool.Begin(ctx)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
 		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	log.Println("TF/IDF calculation and insertion completed successfully.")
+	slog.InfoContext(ctx, "TF/IDF tokens stored", "run_id", runID, "tokens", len(customerTokens))
+	return nil
 }
 
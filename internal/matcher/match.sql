@@ -18,28 +18,50 @@ WITH matches AS (
         candidates.state AS candidate_state,
         candidates.zip_code AS candidate_zip_code,
         candidates.phone_number AS candidate_phone_number,
-        candidate_vec.vector_embedding <=> input_vec.vector_embedding AS similarity
-    FROM customer_matching candidates
-    JOIN customer_matching input
-        ON ((candidates.state = input.state OR candidates.zip_code = input.zip_code) 
-            AND (candidates.zip_code = input.zip_code OR candidates.city = input.city OR candidates.phone_number = input.phone_number))
-    JOIN customer_vector_embedding candidate_vec
-        ON (candidate_vec.customer_id = candidates.customer_id AND candidate_vec.run_id = candidates.run_id)
+        nearest.similarity,
+        input.tenant_id AS input_tenant_id,
+        candidates.tenant_id AS candidate_tenant_id
+    FROM customer_matching input
     JOIN customer_vector_embedding input_vec
-        ON (input_vec.customer_id = input.customer_id AND input_vec.run_id = input.run_id)
-    WHERE candidates.run_id = 0
-    AND input.run_id = $1
+        ON (input_vec.tenant_id = input.tenant_id AND input_vec.customer_id = input.customer_id AND input_vec.run_id = input.run_id)
+    -- Top-k nearest candidates per input, ordered by distance so the vector index can serve it
+    CROSS JOIN LATERAL (
+        SELECT 
+            candidate_vec.tenant_id,
+            candidate_vec.customer_id,
+            candidate_vec.run_id,
+            candidate_vec.vector_embedding <=> input_vec.vector_embedding AS similarity
+        FROM customer_vector_embedding candidate_vec
+        JOIN customer_matching blocked
+            ON (blocked.tenant_id = candidate_vec.tenant_id AND blocked.customer_id = candidate_vec.customer_id AND blocked.run_id = candidate_vec.run_id)
+        WHERE candidate_vec.run_id = 0
+        AND candidate_vec.tenant_id = input.tenant_id{{range .Candidates}}
+        AND {{.}}{{end}}
+        AND ((blocked.state = input.state OR blocked.zip_code = input.zip_code) 
+            AND (blocked.zip_code = input.zip_code OR blocked.city = input.city OR blocked.phone_number = input.phone_number))
+        ORDER BY candidate_vec.vector_embedding <=> input_vec.vector_embedding
+        LIMIT $3
+    ) nearest
+    JOIN customer_matching candidates
+        ON (candidates.tenant_id = nearest.tenant_id AND candidates.customer_id = nearest.customer_id AND candidates.run_id = nearest.run_id)
+    WHERE {{.Input}}
+    AND input.tenant_id = $4
+    AND nearest.similarity <= $2
 ),
 bin_keys AS (
-    SELECT 
-        input.customer_id AS input_customer_id,
+    SELECT DISTINCT
+        matches.input_customer_id,
         match.customer_id AS match_customer_id
-    FROM customer_keys input
+    FROM matches
+    JOIN customer_keys input
+        ON (input.tenant_id = matches.input_tenant_id
+            AND input.run_id = matches.input_run_id
+            AND input.customer_id = matches.input_customer_id)
     JOIN customer_keys match
-        ON (input.binary_key = match.binary_key)
-    JOIN matches
-        ON (matches.input_customer_id = input.customer_id
-            AND matches.candidate_customer_id = match.customer_id)
+        ON (match.tenant_id = matches.candidate_tenant_id
+            AND match.run_id = matches.candidate_run_id
+            AND match.customer_id = matches.candidate_customer_id
+            AND match.binary_key = input.binary_key)
 )
 SELECT 
     COALESCE(matches.input_customer_id, 0) AS input_customer_id,
@@ -63,20 +85,23 @@ SELECT
     COALESCE(matches.similarity, 1) AS similarity,
     CASE WHEN bin_keys.match_customer_id IS NULL THEN FALSE ELSE TRUE END AS bin_key_match,
     SUM(COALESCE(input_tfidf.ngram_tfidf, 0) * COALESCE(candidate_tfidf.ngram_tfidf, 0)) AS tfidf_score,
-    RANK() OVER (PARTITION BY matches.input_customer_id ORDER BY matches.similarity) AS rank
+    RANK() OVER (PARTITION BY matches.input_customer_id ORDER BY matches.similarity) AS rank,
+    matches.input_tenant_id,
+    matches.candidate_tenant_id
 FROM matches
 JOIN customer_tokens input_tfidf
-    ON (input_tfidf.run_id = matches.input_run_id 
+    ON (input_tfidf.tenant_id = matches.input_tenant_id
+        AND input_tfidf.run_id = matches.input_run_id 
         AND input_tfidf.customer_id = matches.input_customer_id)
 JOIN customer_tokens candidate_tfidf
-    ON (candidate_tfidf.run_id = matches.candidate_run_id 
+    ON (candidate_tfidf.tenant_id = matches.candidate_tenant_id
+        AND candidate_tfidf.run_id = matches.candidate_run_id 
         AND candidate_tfidf.customer_id = matches.candidate_customer_id 
         AND candidate_tfidf.entity_type_id = input_tfidf.entity_type_id 
         AND candidate_tfidf.ngram_token = input_tfidf.ngram_token)
 LEFT OUTER JOIN bin_keys
     ON (bin_keys.input_customer_id = matches.input_customer_id 
         AND bin_keys.match_customer_id = matches.candidate_customer_id)
-WHERE matches.similarity <= 0.12
 GROUP BY matches.input_customer_id,
          matches.input_run_id,
          matches.input_first_name,
@@ -96,6 +121,8 @@ GROUP BY matches.input_customer_id,
          matches.candidate_zip_code,
          matches.candidate_phone_number,
          CASE WHEN bin_keys.match_customer_id IS NULL THEN FALSE ELSE TRUE END,
-         matches.similarity
+         matches.similarity,
+         matches.input_tenant_id,
+         matches.candidate_tenant_id
 ORDER BY matches.input_customer_id, matches.similarity;
 
@@ -0,0 +1,96 @@
+WITH matches AS (
+    SELECT 
+        input.customer_id AS input_customer_id,
+        input.run_id AS input_run_id,
+        input.first_name AS input_first_name,
+        input.last_name AS input_last_name,
+        input.street AS input_street,
+        input.city AS input_city,
+        input.state AS input_state,
+        input.zip_code AS input_zip_code,
+        input.phone_number AS input_phone_number,
+        candidates.customer_id AS candidate_customer_id,
+        candidates.run_id AS candidate_run_id,
+        candidates.first_name AS candidate_first_name,
+        candidates.last_name AS candidate_last_name,
+        candidates.street AS candidate_street,
+        candidates.city AS candidate_city,
+        candidates.state AS candidate_state,
+        candidates.zip_code AS candidate_zip_code,
+        candidates.phone_number AS candidate_phone_number,
+        input.tenant_id AS input_tenant_id,
+        candidates.tenant_id AS candidate_tenant_id
+    FROM customer_keys input_key
+    JOIN customer_keys candidate_key
+        ON (candidate_key.binary_key = input_key.binary_key AND candidate_key.run_id = 0 AND candidate_key.tenant_id = input_key.tenant_id)
+    JOIN customer_matching input
+        ON (input.tenant_id = input_key.tenant_id AND input.customer_id = input_key.customer_id AND input.run_id = input_key.run_id)
+    JOIN customer_matching candidates
+        ON (candidates.tenant_id = candidate_key.tenant_id AND candidates.customer_id = candidate_key.customer_id AND candidates.run_id = candidate_key.run_id)
+    WHERE input_key.run_id = $1
+    AND input_key.tenant_id = $3
+    AND ((candidates.state = input.state OR candidates.zip_code = input.zip_code) 
+        AND (candidates.zip_code = input.zip_code OR candidates.city = input.city OR candidates.phone_number = input.phone_number))
+),
+scored AS (
+    SELECT 
+        matches.input_customer_id,
+        matches.input_run_id,
+        COALESCE(matches.input_first_name, '') AS input_first_name,
+        COALESCE(matches.input_last_name, '') AS input_last_name,
+        COALESCE(matches.input_street, '') AS input_street,
+        COALESCE(matches.input_city, '') AS input_city,
+        COALESCE(matches.input_state, '') AS input_state,
+        COALESCE(matches.input_zip_code, '') AS input_zip_code,
+        COALESCE(matches.input_phone_number, '') AS input_phone_number,
+        matches.candidate_customer_id,
+        matches.candidate_run_id,
+        COALESCE(matches.candidate_first_name, '') AS candidate_first_name,
+        COALESCE(matches.candidate_last_name, '') AS candidate_last_name,
+        COALESCE(matches.candidate_street, '') AS candidate_street,
+        COALESCE(matches.candidate_city, '') AS candidate_city,
+        COALESCE(matches.candidate_state, '') AS candidate_state,
+        COALESCE(matches.candidate_zip_code, '') AS candidate_zip_code,
+        COALESCE(matches.candidate_phone_number, '') AS candidate_phone_number,
+        1::FLOAT8 AS similarity,
+        TRUE AS bin_key_match,
+        COALESCE(SUM(input_tfidf.ngram_tfidf * candidate_tfidf.ngram_tfidf), 0) AS tfidf_score,
+        ROW_NUMBER() OVER (PARTITION BY matches.input_customer_id ORDER BY COALESCE(SUM(input_tfidf.ngram_tfidf * candidate_tfidf.ngram_tfidf), 0) DESC) AS rank,
+        matches.input_tenant_id,
+        matches.candidate_tenant_id
+    FROM matches
+    LEFT OUTER JOIN customer_tokens input_tfidf
+        ON (input_tfidf.tenant_id = matches.input_tenant_id
+            AND input_tfidf.run_id = matches.input_run_id 
+            AND input_tfidf.customer_id = matches.input_customer_id)
+    LEFT OUTER JOIN customer_tokens candidate_tfidf
+        ON (candidate_tfidf.tenant_id = matches.candidate_tenant_id
+            AND candidate_tfidf.run_id = matches.candidate_run_id 
+            AND candidate_tfidf.customer_id = matches.candidate_customer_id 
+            AND candidate_tfidf.entity_type_id = input_tfidf.entity_type_id 
+            AND candidate_tfidf.ngram_token = input_tfidf.ngram_token)
+    GROUP BY matches.input_customer_id,
+             matches.input_run_id,
+             matches.input_first_name,
+             matches.input_last_name,
+             matches.input_street,
+             matches.input_city,
+             matches.input_state,
+             matches.input_zip_code,
+             matches.input_phone_number,
+             matches.candidate_customer_id,
+             matches.candidate_run_id,
+             matches.candidate_first_name,
+             matches.candidate_last_name,
+             matches.candidate_street,
+             matches.candidate_city,
+             matches.candidate_state,
+             matches.candidate_zip_code,
+             matches.candidate_phone_number,
+             matches.input_tenant_id,
+             matches.candidate_tenant_id
+)
+SELECT *
+FROM scored
+WHERE rank <= $2
+ORDER BY input_customer_id, rank;
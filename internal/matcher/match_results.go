@@ -0,0 +1,150 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// matchResultColumns are the match_results columns holding a Candidate, in field order
+var matchResultColumns = []string{
+	"input_customer_id", "input_run_id", "input_first_name", "input_last_name", "input_street",
+	"input_city", "input_state", "input_zip_code", "input_phone_number",
+	"candidate_customer_id", "candidate_run_id", "candidate_first_name", "candidate_last_name", "candidate_street",
+	"candidate_city", "candidate_state", "candidate_zip_code", "candidate_phone_number",
+	"similarity", "bin_key_match", "tfidf_score", "rank", "score",
+	"trigram_cosine_first_name", "trigram_cosine_last_name", "trigram_cosine_street",
+	"trigram_cosine_city", "trigram_cosine_phone_number", "trigram_cosine_zip_code",
+	"returned",
+}
+
+// MatchResultFilter narrows the stored results of a run
+type MatchResultFilter struct {
+	MinScore        float64
+	InputCustomerID int
+	// Returned keeps only the candidates returned to the caller
+	Returned bool
+}
+
+// StoreMatchResults writes every scored candidate of a run into match_results,
+// with its rank by score and whether it was returned to the caller. Storing
+// a run again replaces its previous results.
+func StoreMatchResults(ctx context.Context, pool *pgxpool.Pool, runID int, strategy string, candidates []Candidate) error {
+	ctx, st := startStage(ctx, StageStoreResults, runID)
+	addRows(ctx, StageStoreResults, len(candidates))
+	err := storeMatchResults(ctx, pool, runID, strategy, candidates)
+	st.end(err)
+	return stageError(StageStoreResults, err)
+}
+
+func storeMatchResults(ctx context.Context, pool *pgxpool.Pool, runID int, strategy string, candidates []Candidate) error {
+	rows := make([][]interface{}, 0, len(candidates))
+	for _, c := range candidates {
+		rows = append(rows, []interface{}{runID, strategy,
+			c.InputCustomerID, c.InputRunID, c.InputFirstName, c.InputLastName, c.InputStreet,
+			c.InputCity, c.InputState, c.InputZipCode, c.InputPhoneNumber,
+			c.CandidateCustomerID, c.CandidateRunID, c.CandidateFirstName, c.CandidateLastName, c.CandidateStreet,
+			c.CandidateCity, c.CandidateState, c.CandidateZipCode, c.CandidatePhoneNumber,
+			c.Similarity, c.BinKeyMatch, c.TfidfScore, c.Rank, c.Score,
+			c.TrigramCosineFirstName, c.TrigramCosineLastName, c.TrigramCosineStreet,
+			c.TrigramCosineCity, c.TrigramCosinePhoneNumber, c.TrigramCosineZipCode,
+			c.Returned,
+		})
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM match_results WHERE run_id = $1", runID); err != nil {
+		return fmt.Errorf("failed to clear match results: %w", err)
+	}
+	columns := append([]string{"run_id", "strategy"}, matchResultColumns...)
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"match_results"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("failed to store match results: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "stored match results", "run_id", runID, "results", len(rows))
+	return nil
+}
+
+// LoadMatchResults returns the stored results of a run ordered by input
+// customer id, then by rank. It returns ErrNotFound when the
+// run does not exist or belongs to another tenant.
+func LoadMatchResults(ctx context.Context, pool *pgxpool.Pool, runID int, filter MatchResultFilter) ([]Candidate, error) {
+	if err := checkRunTenant(ctx, pool, runID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT input_customer_id, input_run_id, COALESCE(input_first_name, ''), COALESCE(input_last_name, ''),
+		       COALESCE(input_street, ''), COALESCE(input_city, ''), COALESCE(input_state, ''),
+		       COALESCE(input_zip_code, ''), COALESCE(input_phone_number, ''),
+		       candidate_customer_id, candidate_run_id, COALESCE(candidate_first_name, ''), COALESCE(candidate_last_name, ''),
+		       COALESCE(candidate_street, ''), COALESCE(candidate_city, ''), COALESCE(candidate_state, ''),
+		       COALESCE(candidate_zip_code, ''), COALESCE(candidate_phone_number, ''),
+		       similarity, bin_key_match, tfidf_score, rank, score,
+		       trigram_cosine_first_name, trigram_cosine_last_name, trigram_cosine_street,
+		       trigram_cosine_city, trigram_cosine_phone_number, trigram_cosine_zip_code, returned
+		FROM match_results
+		WHERE run_id = $1 AND score >= $2 AND ($3::INT = 0 OR input_customer_id = $3) AND (returned OR NOT $4)
+		ORDER BY input_customer_id, rank`
+
+	rows, err := pool.Query(ctx, query, runID, filter.MinScore, filter.InputCustomerID, filter.Returned)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []Candidate{}
+	for rows.Next() {
+		var c Candidate
+		if err := rows.Scan(&c.InputCustomerID, &c.InputRunID, &c.InputFirstName, &c.InputLastName, &c.InputStreet,
+			&c.InputCity, &c.InputState, &c.InputZipCode, &c.InputPhoneNumber,
+			&c.CandidateCustomerID, &c.CandidateRunID, &c.CandidateFirstName, &c.CandidateLastName, &c.CandidateStreet,
+			&c.CandidateCity, &c.CandidateState, &c.CandidateZipCode, &c.CandidatePhoneNumber,
+			&c.Similarity, &c.BinKeyMatch, &c.TfidfScore, &c.Rank, &c.Score,
+			&c.TrigramCosineFirstName, &c.TrigramCosineLastName, &c.TrigramCosineStreet,
+			&c.TrigramCosineCity, &c.TrigramCosinePhoneNumber, &c.TrigramCosineZipCode, &c.Returned); err != nil {
+			return nil, err
+		}
+		results = append(results, c)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,73 @@
+WITH scored_tokens AS (
+    SELECT 
+        input_tfidf.customer_id AS input_customer_id,
+        candidate_tfidf.customer_id AS candidate_customer_id,
+        SUM(input_tfidf.ngram_tfidf * candidate_tfidf.ngram_tfidf) AS tfidf_score,
+        ROW_NUMBER() OVER (
+            PARTITION BY input_tfidf.customer_id
+            ORDER BY SUM(input_tfidf.ngram_tfidf * candidate_tfidf.ngram_tfidf) DESC
+        ) AS candidate_rank
+    FROM customer_tokens input_tfidf
+    JOIN customer_tokens candidate_tfidf
+        ON (candidate_tfidf.tenant_id = input_tfidf.tenant_id
+            AND candidate_tfidf.entity_type_id = input_tfidf.entity_type_id 
+            AND candidate_tfidf.ngram_token = input_tfidf.ngram_token)
+    JOIN customer_matching input
+        ON (input.tenant_id = input_tfidf.tenant_id AND input.customer_id = input_tfidf.customer_id AND input.run_id = input_tfidf.run_id)
+    JOIN customer_matching candidates
+        ON (candidates.tenant_id = candidate_tfidf.tenant_id AND candidates.customer_id = candidate_tfidf.customer_id AND candidates.run_id = candidate_tfidf.run_id)
+    WHERE input_tfidf.run_id = $1
+    AND input_tfidf.tenant_id = $4
+    AND candidate_tfidf.run_id = 0
+    AND ((candidates.state = input.state OR candidates.zip_code = input.zip_code) 
+        AND (candidates.zip_code = input.zip_code OR candidates.city = input.city OR candidates.phone_number = input.phone_number))
+    GROUP BY input_tfidf.customer_id, candidate_tfidf.customer_id
+    HAVING SUM(input_tfidf.ngram_tfidf * candidate_tfidf.ngram_tfidf) >= $2
+),
+token_scores AS (
+    SELECT input_customer_id, candidate_customer_id, tfidf_score
+    FROM scored_tokens
+    WHERE candidate_rank <= $3
+)
+SELECT 
+    input.customer_id AS input_customer_id,
+    input.run_id AS input_run_id,
+    COALESCE(input.first_name, '') AS input_first_name,
+    COALESCE(input.last_name, '') AS input_last_name,
+    COALESCE(input.street, '') AS input_street,
+    COALESCE(input.city, '') AS input_city,
+    COALESCE(input.state, '') AS input_state,
+    COALESCE(input.zip_code, '') AS input_zip_code,
+    COALESCE(input.phone_number, '') AS input_phone_number,
+    candidates.customer_id AS candidate_customer_id,
+    candidates.run_id AS candidate_run_id,
+    COALESCE(candidates.first_name, '') AS candidate_first_name,
+    COALESCE(candidates.last_name, '') AS candidate_last_name,
+    COALESCE(candidates.street, '') AS candidate_street,
+    COALESCE(candidates.city, '') AS candidate_city,
+    COALESCE(candidates.state, '') AS candidate_state,
+    COALESCE(candidates.zip_code, '') AS candidate_zip_code,
+    COALESCE(candidates.phone_number, '') AS candidate_phone_number,
+    1::FLOAT8 AS similarity,
+    EXISTS (
+        SELECT 1
+        FROM customer_keys input_key
+        JOIN customer_keys candidate_key
+            ON (candidate_key.binary_key = input_key.binary_key)
+        WHERE input_key.tenant_id = input.tenant_id
+        AND input_key.run_id = input.run_id
+        AND input_key.customer_id = input.customer_id
+        AND candidate_key.tenant_id = candidates.tenant_id
+        AND candidate_key.run_id = candidates.run_id
+        AND candidate_key.customer_id = candidates.customer_id
+    ) AS bin_key_match,
+    token_scores.tfidf_score,
+    RANK() OVER (PARTITION BY input.customer_id ORDER BY token_scores.tfidf_score DESC) AS rank,
+    input.tenant_id AS input_tenant_id,
+    candidates.tenant_id AS candidate_tenant_id
+FROM token_scores
+JOIN customer_matching input
+    ON (input.tenant_id = $4 AND input.customer_id = token_scores.input_customer_id AND input.run_id = $1)
+JOIN customer_matching candidates
+    ON (candidates.tenant_id = $4 AND candidates.customer_id = token_scores.candidate_customer_id AND candidates.run_id = 0)
+ORDER BY input.customer_id, token_scores.tfidf_score DESC;
@@ -31,11 +31,13 @@ package matcher
 
 import (
 	"context"
-	"log"
-	"math"
-	"os"
+	"fmt"
+	"log/slog"
 	"sort"
+	"time"
 
+	"github.com/TFMV/AddressMatchPro/internal/metrics"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -49,9 +51,188 @@ type MatchRequest struct {
 	City        string `json:"city"`
 	State       string `json:"state"`
 	ZipCode     string `json:"zip_code"`
-	TopN        int    `json:"top_n"`
+	TopN        *int   `json:"top_n,omitempty"`
 	RunID       int    `json:"run_id"`
-	ScriptPath  string `json:"script_path"`
+	Strategy    string `json:"strategy"`
+	// Optional overrides of the configured matching thresholds
+	MaxDistance    *float64 `json:"max_distance,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+	MinScore       *float64 `json:"min_score,omitempty"`
+	// Group returns one {input, candidates} entry per input record
+	Group bool `json:"group"`
+	// Explain attaches a score explanation to every candidate
+	Explain bool `json:"explain"`
+}
+
+// LogValue logs the request by field name, so that the PII fields are
+// redacted by the logger
+func (r MatchRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("first_name", r.FirstName),
+		slog.String("last_name", r.LastName),
+		slog.String("phone_number", r.PhoneNumber),
+		slog.String("street", r.Street),
+		slog.String("city", r.City),
+		slog.String("state", r.State),
+		slog.String("zip_code", r.ZipCode),
+		slog.Any("top_n", optional(r.TopN)),
+		slog.String("strategy", r.Strategy),
+		slog.Any("max_distance", optional(r.MaxDistance)),
+		slog.Any("candidate_limit", optional(r.CandidateLimit)),
+		slog.Any("min_score", optional(r.MinScore)),
+		slog.Bool("group", r.Group),
+		slog.Bool("explain", r.Explain),
+	)
+}
+
+// MatchOptions controls how candidates are generated and how many are returned
+type MatchOptions struct {
+	Strategy string
+	TopN     int
+	// MaxDistance is the largest cosine distance a vector candidate may have
+	MaxDistance float64
+	// CandidateLimit caps the candidates pulled per input record by the query
+	CandidateLimit int
+	// MinScore drops scored candidates below this composite score
+	MinScore      float64
+	MinTfidfScore float64
+	// EfSearch and Probes tune the HNSW and IVFFlat index scans for the query
+	EfSearch int
+	Probes   int
+	// MaxScanTuples caps the rows an iterative HNSW scan visits while looking
+	// for candidates that pass the filters; zero keeps pgvector's default
+	MaxScanTuples int
+	// Explain attaches a score explanation to every candidate
+	Explain bool
+	// Profile weights the score features; nil uses the strategy's profile
+	Profile *ScoringProfile
+}
+
+// DefaultMatchOptions returns the options used when a request leaves them unset
+func DefaultMatchOptions() MatchOptions {
+	return MatchOptions{
+		Strategy:       DefaultStrategy,
+		TopN:           10,
+		MaxDistance:    0.12,
+		CandidateLimit: 50,
+		MinScore:       1,
+		MinTfidfScore:  0.1,
+	}
+}
+
+// MatchOverrides holds the matching options a caller sets explicitly. Nil
+// fields keep the defaults; set fields are applied as given, zero and
+// negative values included, and left for Validate to reject.
+type MatchOverrides struct {
+	Strategy       string
+	TopN           *int
+	MaxDistance    *float64
+	CandidateLimit *int
+	MinScore       *float64
+	MinTfidfScore  *float64
+	EfSearch       *int
+	Probes         *int
+	MaxScanTuples  *int
+	Explain        *bool
+	Profile        *ScoringProfile
+}
+
+// Ptr returns a pointer to v, for setting MatchOverrides and request fields
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// optional returns the value p points to, or nil
+func optional[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// Merge returns a copy of o with every set field of override applied
+func (o MatchOptions) Merge(override MatchOverrides) MatchOptions {
+	if override.Strategy != "" {
+		o.Strategy = override.Strategy
+	}
+	if override.TopN != nil {
+		o.TopN = *override.TopN
+	}
+	if override.MaxDistance != nil {
+		o.MaxDistance = *override.MaxDistance
+	}
+	if override.CandidateLimit != nil {
+		o.CandidateLimit = *override.CandidateLimit
+	}
+	if override.MinScore != nil {
+		o.MinScore = *override.MinScore
+	}
+	if override.MinTfidfScore != nil {
+		o.MinTfidfScore = *override.MinTfidfScore
+	}
+	if override.EfSearch != nil {
+		o.EfSearch = *override.EfSearch
+	}
+	if override.Probes != nil {
+		o.Probes = *override.Probes
+	}
+	if override.MaxScanTuples != nil {
+		o.MaxScanTuples = *override.MaxScanTuples
+	}
+	if override.Explain != nil {
+		o.Explain = *override.Explain
+	}
+	if override.Profile != nil {
+		o.Profile = override.Profile
+	}
+	return o
+}
+
+// Validate checks that the options can be passed to a match query
+func (o MatchOptions) Validate() error {
+	if _, err := LookupStrategy(o.Strategy); err != nil {
+		return err
+	}
+	if o.TopN <= 0 {
+		return fmt.Errorf("top_n must be positive, got %d", o.TopN)
+	}
+	if o.MaxDistance <= 0 || o.MaxDistance > 2 {
+		return fmt.Errorf("max_distance must be in (0, 2], got %v", o.MaxDistance)
+	}
+	if o.CandidateLimit <= 0 {
+		return fmt.Errorf("candidate_limit must be positive, got %d", o.CandidateLimit)
+	}
+	if o.MinScore < 0 || o.MinScore > 100 {
+		return fmt.Errorf("min_score must be in [0, 100], got %v", o.MinScore)
+	}
+	if o.MinTfidfScore < 0 {
+		return fmt.Errorf("min_tfidf_score must not be negative, got %v", o.MinTfidfScore)
+	}
+	if o.EfSearch < 0 {
+		return fmt.Errorf("ef_search must not be negative, got %d", o.EfSearch)
+	}
+	if o.Probes < 0 {
+		return fmt.Errorf("probes must not be negative, got %d", o.Probes)
+	}
+	if o.MaxScanTuples < 0 {
+		return fmt.Errorf("max_scan_tuples must not be negative, got %d", o.MaxScanTuples)
+	}
+	return nil
+}
+
+// Options merges the request's matching fields over the given defaults
+func (r MatchRequest) Options(defaults MatchOptions) MatchOptions {
+	overrides := MatchOverrides{
+		Strategy:       r.Strategy,
+		TopN:           r.TopN,
+		MaxDistance:    r.MaxDistance,
+		CandidateLimit: r.CandidateLimit,
+		MinScore:       r.MinScore,
+	}
+	if r.Explain {
+		overrides.Explain = Ptr(true)
+	}
+	return defaults.Merge(overrides)
 }
 
 // Candidate represents a potential match
@@ -85,35 +266,224 @@ type Candidate struct {
 	TrigramCosineCity        float64 `json:"trigram_cosine_city"`
 	TrigramCosinePhoneNumber float64 `json:"trigram_cosine_phone_number"`
 	TrigramCosineZipCode     float64 `json:"trigram_cosine_zip_code"`
+	// Explanation is set when the request asks for one
+	Explanation *Explanation `json:"explanation,omitempty"`
+	// Tenants of the two records, checked against the caller's tenant
+	InputTenantID     string `json:"-"`
+	CandidateTenantID string `json:"-"`
+	// Returned is set on the candidates within the top N of their input that
+	// reach the minimum score; the others are only stored
+	Returned bool `json:"-"`
 }
 
-// LoadSQLQuery loads an SQL query from a file
-func LoadSQLQuery(filepath string) (string, error) {
-	queryBytes, err := os.ReadFile(filepath)
+// FindPotentialMatches finds and scores the candidates of a run. Every scored
+// candidate is returned, ranked by score within its input, and those within
+// the top N that reach the minimum score are marked Returned.
+func FindPotentialMatches(ctx context.Context, pool *pgxpool.Pool, runID int, opts MatchOptions) ([]Candidate, error) {
+	strategy, err := LookupStrategy(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := strategy.ScoringProfile(opts)
+	args := strategy.Args(runID, TenantFromContext(ctx), opts)
+	ctx, st := startStage(ctx, StageMatch, runID)
+	candidates, err := queryCandidates(ctx, pool, strategy.StatementName(), strategy.SQL, args, opts, nil)
 	if err != nil {
-		return "", err
+		st.end(err)
+		return nil, stageError(StageMatch, err)
+	}
+	addRows(ctx, StageMatch, len(candidates))
+	st.end(nil)
+
+	for i := range candidates {
+		scoreCandidate(&candidates[i], profile)
+	}
+	RankByScore(candidates)
+	for i := range candidates {
+		c := &candidates[i]
+		c.Returned = c.Rank <= opts.TopN && c.Score >= opts.MinScore
+		if c.Returned && opts.Explain {
+			ExplainCandidate(c, strategy, opts, profile)
+		}
+	}
+	returned := ReturnedCandidates(candidates)
+	observeCandidates(returned)
+
+	slog.InfoContext(ctx, "candidates found", "run_id", runID, "strategy", strategy.Name, "scored", len(candidates), "returned", len(returned))
+	return candidates, nil
+}
+
+// ReturnedCandidates returns the candidates marked Returned, in order
+func ReturnedCandidates(candidates []Candidate) []Candidate {
+	returned := []Candidate{}
+	for _, c := range candidates {
+		if c.Returned {
+			returned = append(returned, c)
+		}
+	}
+	return returned
+}
+
+// RankByScore orders candidates by input customer id, then by descending
+// score, and numbers the candidates of each input from 1 in that order
+func RankByScore(candidates []Candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].InputCustomerID != candidates[j].InputCustomerID {
+			return candidates[i].InputCustomerID < candidates[j].InputCustomerID
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+	for i := range candidates {
+		if i == 0 || candidates[i].InputCustomerID != candidates[i-1].InputCustomerID {
+			candidates[i].Rank = 1
+		} else {
+			candidates[i].Rank = candidates[i-1].Rank + 1
+		}
 	}
-	return string(queryBytes), nil
 }
 
-// FindPotentialMatches finds potential matches and scores them based on composite score
-func FindPotentialMatches(pool *pgxpool.Pool, runID int, topN int) ([]Candidate, error) {
-	query, err := LoadSQLQuery("/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/internal/matcher/match.sql")
+// observeCandidates records the candidates per input and their scores
+func observeCandidates(candidates []Candidate) {
+	perInput := make(map[int]int)
+	for _, c := range candidates {
+		perInput[c.InputCustomerID]++
+		metrics.MatchScores.Observe(c.Score)
+	}
+	for _, n := range perInput {
+		metrics.CandidatesPerInput.Observe(float64(n))
+	}
+}
+
+// TopNPerInput keeps the topN highest scoring candidates of every input record.
+// The result is ordered by input customer id, then by descending score.
+func TopNPerInput(candidates []Candidate, topN int) []Candidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].InputCustomerID != candidates[j].InputCustomerID {
+			return candidates[i].InputCustomerID < candidates[j].InputCustomerID
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	kept := candidates[:0]
+	perInput := 0
+	for i, candidate := range candidates {
+		if i == 0 || candidate.InputCustomerID != candidates[i-1].InputCustomerID {
+			perInput = 0
+		}
+		if perInput < topN {
+			kept = append(kept, candidate)
+		}
+		perInput++
+	}
+	return kept
+}
+
+// InputRecord is a record of a run that was matched against the candidate
+// space. SourceSystem and UpdatedAt are the provenance the most_recent and
+// preferred_source survivorship rules read; they are only stored on load.
+type InputRecord struct {
+	CustomerID   int        `json:"customer_id"`
+	RunID        int        `json:"run_id"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	Street       string     `json:"street"`
+	City         string     `json:"city"`
+	State        string     `json:"state"`
+	ZipCode      string     `json:"zip_code"`
+	PhoneNumber  string     `json:"phone_number"`
+	SourceSystem string     `json:"source_system,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// MatchResult groups the candidates of one input record. Matched is false,
+// and Candidates empty, when no candidate was found for the input.
+type MatchResult struct {
+	Input      InputRecord `json:"input"`
+	Matched    bool        `json:"matched"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// LoadRunInputs returns the input records of a run ordered by customer id
+func LoadRunInputs(ctx context.Context, pool *pgxpool.Pool, runID int) ([]InputRecord, error) {
+	rows, err := pool.Query(ctx,
+		`SELECT customer_id, run_id, COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(street, ''),
+		        COALESCE(city, ''), COALESCE(state, ''), COALESCE(zip_code, ''), COALESCE(phone_number, '')
+		 FROM customer_matching WHERE run_id = $1 AND tenant_id = $2 ORDER BY customer_id`, runID, TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanInputRecord)
+}
+
+// scanInputRecord scans the nine customer_matching columns of an InputRecord
+func scanInputRecord(row pgx.CollectableRow) (InputRecord, error) {
+	var in InputRecord
+	err := row.Scan(&in.CustomerID, &in.RunID, &in.FirstName, &in.LastName, &in.Street,
+		&in.City, &in.State, &in.ZipCode, &in.PhoneNumber)
+	return in, err
+}
+
+// GroupByInput returns one MatchResult per input record, in input order, so
+// that batch results line up with the submitted rows
+func GroupByInput(inputs []InputRecord, candidates []Candidate) []MatchResult {
+	byInput := make(map[int][]Candidate, len(inputs))
+	for _, candidate := range candidates {
+		byInput[candidate.InputCustomerID] = append(byInput[candidate.InputCustomerID], candidate)
+	}
+
+	results := make([]MatchResult, 0, len(inputs))
+	for _, input := range inputs {
+		matched := byInput[input.CustomerID]
+		if matched == nil {
+			matched = []Candidate{}
+		}
+		results = append(results, MatchResult{
+			Input:      input,
+			Matched:    len(matched) > 0,
+			Candidates: matched,
+		})
+	}
+	return results
+}
+
+// queryCandidates runs a prepared candidate query inside a transaction carrying
+// the ANN search settings and returns the rows that keep accepts, or every row
+// when keep is nil. Queries end with the input and candidate tenant columns; a
+// row of any tenant but the one of ctx fails the query.
+func queryCandidates(ctx context.Context, pool *pgxpool.Pool, name string, sql string, args []interface{}, opts MatchOptions, keep func(*Candidate) bool) ([]Candidate, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	// Prepare is a no-op once the statement exists on this connection
+	if _, err := conn.Conn().Prepare(ctx, name, sql); err != nil {
+		return nil, err
+	}
+
+	// The ANN search settings only apply inside this transaction
+	tx, err := conn.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
+	if err := applySearchSettings(ctx, tx, opts); err != nil {
+		return nil, err
+	}
 
-	// Log the query and the runID parameter
-	log.Printf("Executing query with runID: %d\nQuery: %s\n", runID, query)
+	slog.DebugContext(ctx, "executing candidate query", "statement", name)
 
 	// Execute the query
-	rows, err := pool.Query(context.Background(), query, runID)
+	rows, err := tx.Query(ctx, name, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var candidates []Candidate
+	tenant := TenantFromContext(ctx)
 
 	// Iterate through the rows and populate the candidates slice
 	for rows.Next() {
@@ -146,9 +516,14 @@ func FindPotentialMatches(pool *pgxpool.Pool, runID int, topN int) ([]Candidate,
 			&binKeyMatch,
 			&candidate.TfidfScore,
 			&rank,
+			&candidate.InputTenantID,
+			&candidate.CandidateTenantID,
 		); err != nil {
 			return nil, err
 		}
+		if err := CheckTenant(tenant, candidate); err != nil {
+			return nil, err
+		}
 
 		// Convert pgtype.Text to string
 		candidate.InputFirstName = inputFirstName.String
@@ -168,45 +543,10 @@ func FindPotentialMatches(pool *pgxpool.Pool, runID int, topN int) ([]Candidate,
 		candidate.BinKeyMatch = binKeyMatch.Bool
 		candidate.Rank = int(rank.Int32)
 
-		// Calculate n-gram similarities
-		candidate.TrigramCosineFirstName = ngramFrequencySimilarity(candidate.InputFirstName, candidate.CandidateFirstName, 2)
-		candidate.TrigramCosineLastName = ngramFrequencySimilarity(candidate.InputLastName, candidate.CandidateLastName, 2)
-		candidate.TrigramCosineStreet = ngramFrequencySimilarity(candidate.InputStreet, candidate.CandidateStreet, 2)
-		candidate.TrigramCosineCity = ngramFrequencySimilarity(candidate.InputCity, candidate.CandidateCity, 2)
-		candidate.TrigramCosinePhoneNumber = ngramFrequencySimilarity(candidate.InputPhoneNumber, candidate.CandidatePhoneNumber, 2)
-		candidate.TrigramCosineZipCode = ngramFrequencySimilarity(candidate.InputZipCode, candidate.CandidateZipCode, 2)
-
-		// Compute the normalized similarity score
-		normalizedSimilarity := 1 - candidate.Similarity
-
-		// Define weights for each feature based on its importance
-		weights := map[string]float64{
-			"similarity":  0.25,
-			"tfidf":       0.2,
-			"firstName":   0.1,
-			"lastName":    0.1,
-			"street":      0.1,
-			"city":        0.1,
-			"phoneNumber": 0.05,
-			"zipCode":     0.05,
-			"binKeyMatch": 0.05,
-		}
-
-		// Adjust composite score based on binary key match
-		binKeyMatchScore := 0.0
-		if candidate.BinKeyMatch {
-			binKeyMatchScore = 1.0
+		if keep != nil && !keep(&candidate) {
+			continue
 		}
 
-		// Calculate composite score based on weighted features
-		compositeScore := normalizedSimilarity*weights["similarity"] + candidate.TfidfScore*weights["tfidf"] +
-			candidate.TrigramCosineFirstName*weights["firstName"] + candidate.TrigramCosineLastName*weights["lastName"] +
-			candidate.TrigramCosineStreet*weights["street"] + candidate.TrigramCosineCity*weights["city"] +
-			candidate.TrigramCosinePhoneNumber*weights["phoneNumber"] + candidate.TrigramCosineZipCode*weights["zipCode"] +
-			binKeyMatchScore*weights["binKeyMatch"]
-
-		candidate.Score = math.Max(1, math.Min(100, compositeScore*100))
-
 		candidates = append(candidates, candidate)
 	}
 
@@ -215,18 +555,82 @@ func FindPotentialMatches(pool *pgxpool.Pool, runID int, topN int) ([]Candidate,
 		return nil, err
 	}
 
-	log.Printf("Total candidates found: %d\n", len(candidates)) // Log the total number of candidates found
+	return candidates, nil
+}
 
-	// Sort candidates by score in descending order
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].Score > candidates[j].Score
-	})
+// ScoringProfile weights the candidate features in the composite score
+type ScoringProfile struct {
+	Similarity  float64
+	Tfidf       float64
+	FirstName   float64
+	LastName    float64
+	Street      float64
+	City        float64
+	PhoneNumber float64
+	ZipCode     float64
+	BinKeyMatch float64
+}
 
-	// If the number of candidates exceeds the requested TopN, truncate the list
-	if len(candidates) > topN {
-		candidates = candidates[:topN]
+// PersonScoringProfile weights name and address together to identify one person
+func PersonScoringProfile() ScoringProfile {
+	return ScoringProfile{
+		Similarity:  0.25,
+		Tfidf:       0.2,
+		FirstName:   0.1,
+		LastName:    0.1,
+		Street:      0.1,
+		City:        0.1,
+		PhoneNumber: 0.05,
+		ZipCode:     0.05,
+		BinKeyMatch: 0.05,
 	}
+}
 
-	return candidates, nil
+// WithoutSimilarity drops the vector similarity weight and scales the others
+// up to the same total, for candidates found without a vector distance
+func (p ScoringProfile) WithoutSimilarity() ScoringProfile {
+	total := p.Similarity + p.Tfidf + p.FirstName + p.LastName + p.Street + p.City + p.PhoneNumber + p.ZipCode + p.BinKeyMatch
+	rest := total - p.Similarity
+	if rest <= 0 {
+		return ScoringProfile{}
+	}
+	scale := total / rest
+	return ScoringProfile{
+		Tfidf:       p.Tfidf * scale,
+		FirstName:   p.FirstName * scale,
+		LastName:    p.LastName * scale,
+		Street:      p.Street * scale,
+		City:        p.City * scale,
+		PhoneNumber: p.PhoneNumber * scale,
+		ZipCode:     p.ZipCode * scale,
+		BinKeyMatch: p.BinKeyMatch * scale,
+	}
+}
+
+// ScoreCandidate computes the n-gram features and the composite score of a
+// candidate found by the strategy
+func ScoreCandidate(candidate *Candidate, strategy Strategy, opts MatchOptions) {
+	scoreCandidate(candidate, strategy.ScoringProfile(opts))
+}
+
+// scoreCandidate computes the n-gram features and the composite score
+func scoreCandidate(candidate *Candidate, profile ScoringProfile) {
+	// Calculate n-gram similarities
+	candidate.TrigramCosineFirstName = ngramFrequencySimilarity(candidate.InputFirstName, candidate.CandidateFirstName, 2)
+	candidate.TrigramCosineLastName = ngramFrequencySimilarity(candidate.InputLastName, candidate.CandidateLastName, 2)
+	candidate.TrigramCosineStreet = ngramFrequencySimilarity(candidate.InputStreet, candidate.CandidateStreet, 2)
+	candidate.TrigramCosineCity = ngramFrequencySimilarity(candidate.InputCity, candidate.CandidateCity, 2)
+	candidate.TrigramCosinePhoneNumber = ngramFrequencySimilarity(candidate.InputPhoneNumber, candidate.CandidatePhoneNumber, 2)
+	candidate.TrigramCosineZipCode = ngramFrequencySimilarity(candidate.InputZipCode, candidate.CandidateZipCode, 2)
+
+	candidate.Score = compositeScore(candidate, profile)
+}
+
+// scoringProfile returns the configured profile or the person profile
+func (o MatchOptions) scoringProfile() ScoringProfile {
+	if o.Profile != nil {
+		return *o.Profile
+	}
+	return PersonScoringProfile()
 }
 
@@ -0,0 +1,171 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Embedder writes vector embeddings for the records of a run into
+// customer_vector_embedding, tagged with the tenant of ctx
+type Embedder interface {
+	Embed(ctx context.Context, runID int) error
+}
+
+// PythonEmbedder runs the generate_embeddings.py script
+type PythonEmbedder struct {
+	ScriptPath string
+	// Python is the interpreter; python3 when empty
+	Python string
+	// Env holds NAME=value variables added to the script's environment,
+	// such as the PG* connection settings
+	Env []string
+}
+
+// Embed runs the script for the run
+func (e PythonEmbedder) Embed(ctx context.Context, runID int) error {
+	return runEmbeddingScript(ctx, e, runID)
+}
+
+// PrepareRun builds the binary keys, TF/IDF tokens and vector embeddings of a
+// run's records so that they can be matched. Failures are StageErrors.
+func PrepareRun(ctx context.Context, pool *pgxpool.Pool, runID int, workers int, embedder Embedder) error {
+	referenceEntities, err := LoadReferenceEntities(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if err := ProcessCustomerAddresses(ctx, pool, referenceEntities, workers, runID); err != nil {
+		return err
+	}
+	if err := GenerateTFIDF(ctx, pool, runID); err != nil {
+		return err
+	}
+
+	embedCtx, st := startStage(ctx, StageEmbeddings, runID)
+	err = embedder.Embed(embedCtx, runID)
+	st.end(err)
+	return stageError(StageEmbeddings, err)
+}
+
+// InsertRunRecords adds records of the tenant of ctx, with their provenance,
+// to customer_matching under a run. The records' customer ids are kept when
+// every record has one; otherwise all are assigned.
+func InsertRunRecords(ctx context.Context, pool *pgxpool.Pool, runID int, records []InputRecord) error {
+	keepIDs := len(records) > 0
+	for _, r := range records {
+		keepIDs = keepIDs && r.CustomerID > 0
+	}
+
+	tenant := TenantFromContext(ctx)
+	columns := []string{"first_name", "last_name", "phone_number", "street", "city", "state", "zip_code", "source_system", "updated_at", "run_id", "tenant_id"}
+	if keepIDs {
+		columns = append([]string{"customer_id"}, columns...)
+	}
+	rows := make([][]interface{}, 0, len(records))
+	for _, r := range records {
+		row := []interface{}{strings.ToLower(r.FirstName), strings.ToLower(r.LastName), strings.ToLower(r.PhoneNumber),
+			strings.ToLower(r.Street), strings.ToLower(r.City), strings.ToLower(r.State), strings.ToLower(r.ZipCode), r.SourceSystem, r.UpdatedAt, runID, tenant}
+		if keepIDs {
+			row = append([]interface{}{r.CustomerID}, row...)
+		}
+		rows = append(rows, row)
+	}
+
+	if _, err := pool.CopyFrom(ctx, pgx.Identifier{"customer_matching"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return stageError(StageLoadInput, fmt.Errorf("failed to insert records for run %d: %w", runID, err))
+	}
+	return nil
+}
+
+// ReplaceCandidateRecords replaces the records of the tenant's candidate space
+// (run 0). Call RebuildCandidateSpace afterwards to make them matchable.
+func ReplaceCandidateRecords(ctx context.Context, pool *pgxpool.Pool, records []InputRecord) error {
+	for _, r := range records {
+		if r.CustomerID <= 0 {
+			return fmt.Errorf("candidate records need a customer id")
+		}
+	}
+	if _, err := pool.Exec(ctx, "DELETE FROM customer_matching WHERE run_id = 0 AND tenant_id = $1", TenantFromContext(ctx)); err != nil {
+		return stageError(StageLoadInput, err)
+	}
+	return InsertRunRecords(ctx, pool, 0, records)
+}
+
+// SyncCandidateRecords replaces the records of the tenant's candidate space
+// (run 0) with the tenant's customers, with their provenance, in one
+// transaction, and returns how many were copied. Call RebuildCandidateSpace
+// afterwards to make them matchable.
+func SyncCandidateRecords(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	tenant := TenantFromContext(ctx)
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, stageError(StageLoadInput, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM customer_matching WHERE run_id = 0 AND tenant_id = $1", tenant); err != nil {
+		return 0, stageError(StageLoadInput, err)
+	}
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO customer_matching (customer_id, first_name, last_name, phone_number, street, city, state, zip_code, source_system, updated_at, run_id, tenant_id)
+		 SELECT customer_id, LOWER(customer_fname), LOWER(customer_lname), NULL, LOWER(customer_street), LOWER(customer_city), LOWER(customer_state), LOWER(customer_zipcode::TEXT), source_system, updated_at, 0, $1
+		 FROM customers
+		 WHERE tenant_id = $1`, tenant)
+	if err != nil {
+		return 0, stageError(StageLoadInput, fmt.Errorf("failed to copy customers into run 0: %w", err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, stageError(StageLoadInput, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RebuildCandidateSpace clears and rebuilds the keys, tokens, embeddings and
+// ANN index of the tenant's candidate space (run 0) from its customer_matching
+// records. The index is shared by every tenant's run 0.
+func RebuildCandidateSpace(ctx context.Context, pool *pgxpool.Pool, workers int, embedder Embedder, index IndexOptions) error {
+	if _, err := pool.Exec(ctx, "INSERT INTO runs (run_id, description) VALUES (0, 'Default run') ON CONFLICT (run_id) DO NOTHING"); err != nil {
+		return stageError(StageCreateRun, err)
+	}
+	for _, table := range []string{"customer_keys", "customer_tokens", "tokens_idf", "customer_vector_embedding"} {
+		if _, err := pool.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE run_id = 0 AND tenant_id = $1", table), TenantFromContext(ctx)); err != nil {
+			return fmt.Errorf("failed to clear old candidates from %s: %w", table, err)
+		}
+	}
+	if err := PrepareRun(ctx, pool, 0, workers, embedder); err != nil {
+		return err
+	}
+	return BuildVectorIndex(ctx, pool, index)
+}
@@ -0,0 +1,327 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/bits"
+	"strconv"
+)
+
+// PPRLFields are the fields encoded for privacy-preserving record linkage
+var PPRLFields = []string{"first_name", "last_name", "street", "city", "zip_code", "phone_number"}
+
+const (
+	// DefaultFilterBits is the size of each field's Bloom filter
+	DefaultFilterBits = 1024
+	// DefaultFilterHashes is the number of bits set per bigram
+	DefaultFilterHashes = 20
+	// minSecretLength is the shortest shared secret accepted, in bytes
+	minSecretLength = 16
+)
+
+// ErrFilterSize is returned when encodings of different sizes are compared
+var ErrFilterSize = errors.New("bloom filters differ in size")
+
+// BloomFilter is a bit set holding the hashed bigrams of one field
+type BloomFilter []byte
+
+// Dice returns the Dice coefficient of two filters: twice the shared set bits
+// over the total set bits. An empty filter has a similarity of 0.
+func (f BloomFilter) Dice(g BloomFilter) float64 {
+	if len(f) != len(g) {
+		return 0
+	}
+	var shared, total int
+	for i := range f {
+		shared += bits.OnesCount8(f[i] & g[i])
+		total += bits.OnesCount8(f[i]) + bits.OnesCount8(g[i])
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+// CLKEncoder hashes the bigrams of each field into a Bloom filter keyed with
+// a secret shared by the linking parties. Without the secret, the encodings
+// can neither be built from guessed values nor compared with other parties'.
+type CLKEncoder struct {
+	secret []byte
+	bits   int
+	hashes int
+}
+
+// NewCLKEncoder returns an encoder for filters of the given bits and hashes
+// per bigram. Both parties must use the same secret, bits and hashes.
+func NewCLKEncoder(secret []byte, filterBits, hashes int) (CLKEncoder, error) {
+	if len(secret) < minSecretLength {
+		return CLKEncoder{}, fmt.Errorf("secret must be at least %d bytes", minSecretLength)
+	}
+	if filterBits <= 0 || filterBits%8 != 0 {
+		return CLKEncoder{}, fmt.Errorf("filter bits must be a positive multiple of 8, got %d", filterBits)
+	}
+	if hashes <= 0 {
+		return CLKEncoder{}, fmt.Errorf("hashes must be positive, got %d", hashes)
+	}
+	return CLKEncoder{secret: secret, bits: filterBits, hashes: hashes}, nil
+}
+
+// EncodeField returns the filter of a field value, or nil for an empty value.
+// The field name is part of the key, so the same bigram sets different bits
+// in different fields.
+func (e CLKEncoder) EncodeField(field, value string) BloomFilter {
+	if field == "street" {
+		if standardized, err := StandardizeAddress(value); err == nil {
+			value = standardized
+		}
+	}
+	if normalizeString(value) == "" {
+		return nil
+	}
+
+	filter := make(BloomFilter, e.bits/8)
+	mac := hmac.New(sha256.New, e.secret)
+	for _, bigram := range ngrams(value, 2) {
+		h1, h2 := e.hashBigram(mac, field, bigram)
+		// Double hashing derives the k bit positions from two keyed hashes
+		for i := uint64(0); i < uint64(e.hashes); i++ {
+			bit := (h1 + i*h2) % uint64(e.bits)
+			filter[bit/8] |= 1 << (bit % 8)
+		}
+	}
+	return filter
+}
+
+func (e CLKEncoder) hashBigram(mac hash.Hash, field, bigram string) (uint64, uint64) {
+	mac.Reset()
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(bigram))
+	sum := mac.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16]) | 1
+}
+
+// EncodedRecord holds the filters of one customer by field name
+type EncodedRecord struct {
+	CustomerID int
+	Filters    map[string]BloomFilter
+}
+
+// Encode returns the encoded record of a customer's field values
+func (e CLKEncoder) Encode(customerID int, fields map[string]string) EncodedRecord {
+	record := EncodedRecord{CustomerID: customerID, Filters: make(map[string]BloomFilter, len(PPRLFields))}
+	for _, field := range PPRLFields {
+		record.Filters[field] = e.EncodeField(field, fields[field])
+	}
+	return record
+}
+
+// EncodeCSV reads customers from a CSV with a customer_id column and any of
+// the PPRLFields columns, and writes their encodings as a CSV of base64
+// filters. It returns the number of records encoded.
+func EncodeCSV(r io.Reader, w io.Writer, e CLKEncoder) (int, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	idColumn, ok := columns["customer_id"]
+	if !ok {
+		return 0, errors.New("CSV has no customer_id column")
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"customer_id"}, PPRLFields...)); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("error reading CSV: %w", err)
+		}
+		id, err := strconv.Atoi(row[idColumn])
+		if err != nil {
+			return count, fmt.Errorf("invalid customer_id %q", row[idColumn])
+		}
+		fields := make(map[string]string, len(PPRLFields))
+		for _, field := range PPRLFields {
+			if i, ok := columns[field]; ok {
+				fields[field] = row[i]
+			}
+		}
+
+		record := e.Encode(id, fields)
+		out := []string{strconv.Itoa(id)}
+		for _, field := range PPRLFields {
+			out = append(out, base64.StdEncoding.EncodeToString(record.Filters[field]))
+		}
+		if err := writer.Write(out); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// ReadEncoded reads encodings written by EncodeCSV. All non-empty filters
+// must have the same size.
+func ReadEncoded(r io.Reader) ([]EncodedRecord, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading encoded header: %w", err)
+	}
+	if len(header) == 0 || header[0] != "customer_id" {
+		return nil, errors.New("encoded file must start with a customer_id column")
+	}
+
+	var records []EncodedRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading encoded record: %w", err)
+		}
+		id, err := strconv.Atoi(row[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid customer_id %q", row[0])
+		}
+
+		record := EncodedRecord{CustomerID: id, Filters: make(map[string]BloomFilter, len(header)-1)}
+		for i, field := range header[1:] {
+			filter, err := base64.StdEncoding.DecodeString(row[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s filter of customer %d: %v", field, id, err)
+			}
+			if len(filter) > 0 {
+				record.Filters[field] = filter
+			}
+		}
+		records = append(records, record)
+	}
+	if err := checkFilterSizes(records, nil); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// PPRLScoringProfile weights the encoded name and address fields. Encoded
+// records have no vector, TF/IDF or binary key features.
+func PPRLScoringProfile() ScoringProfile {
+	return ScoringProfile{
+		FirstName:   0.2,
+		LastName:    0.25,
+		Street:      0.25,
+		City:        0.1,
+		PhoneNumber: 0.1,
+		ZipCode:     0.1,
+	}
+}
+
+// MatchEncoded compares every input with every candidate on the Dice
+// similarity of their field filters. The similarities are stored in the
+// Candidate's n-gram fields and scored with opts.Profile, or
+// PPRLScoringProfile when it is nil; the cleartext fields stay empty. The
+// topN candidates with at least opts.MinScore are kept per input.
+func MatchEncoded(inputs, candidates []EncodedRecord, opts MatchOptions) ([]Candidate, error) {
+	if err := checkFilterSizes(inputs, candidates); err != nil {
+		return nil, err
+	}
+	profile := PPRLScoringProfile()
+	if opts.Profile != nil {
+		profile = *opts.Profile
+	}
+
+	var matches []Candidate
+	for _, in := range inputs {
+		var perInput []Candidate
+		for _, cand := range candidates {
+			c := Candidate{
+				InputCustomerID:          in.CustomerID,
+				CandidateCustomerID:      cand.CustomerID,
+				TrigramCosineFirstName:   in.Filters["first_name"].Dice(cand.Filters["first_name"]),
+				TrigramCosineLastName:    in.Filters["last_name"].Dice(cand.Filters["last_name"]),
+				TrigramCosineStreet:      in.Filters["street"].Dice(cand.Filters["street"]),
+				TrigramCosineCity:        in.Filters["city"].Dice(cand.Filters["city"]),
+				TrigramCosinePhoneNumber: in.Filters["phone_number"].Dice(cand.Filters["phone_number"]),
+				TrigramCosineZipCode:     in.Filters["zip_code"].Dice(cand.Filters["zip_code"]),
+			}
+			c.Score = compositeScore(&c, profile)
+			if c.Score >= opts.MinScore {
+				perInput = append(perInput, c)
+			}
+		}
+		matches = append(matches, TopNPerInput(perInput, opts.TopN)...)
+	}
+	return matches, nil
+}
+
+// checkFilterSizes reports encodings built with different filter sizes, whose
+// similarities would all be 0
+func checkFilterSizes(inputs, candidates []EncodedRecord) error {
+	size := 0
+	for _, records := range [][]EncodedRecord{inputs, candidates} {
+		for _, record := range records {
+			for field, filter := range record.Filters {
+				if len(filter) == 0 {
+					continue
+				}
+				if size == 0 {
+					size = len(filter)
+				} else if len(filter) != size {
+					return fmt.Errorf("%w: customer %d has a %d byte %s filter, want %d", ErrFilterSize, record.CustomerID, len(filter), field, size)
+				}
+			}
+		}
+	}
+	return nil
+}
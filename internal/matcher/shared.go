@@ -32,47 +32,41 @@ package matcher
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/TFMV/AddressMatchPro/internal/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"gopkg.in/yaml.v2"
 )
 
-type Config struct {
-	DBCreds struct {
-		Host      string `yaml:"host"`
-		Port      string `yaml:"port"`
-		Username  string `yaml:"username"`
-		Password  string `yaml:"password"`
-		Database  string `yaml:"database"`
-		LoadTable string `yaml:"load_table"`
-	} `yaml:"db_creds"`
-}
-
-// Load reference entities into memory
-func LoadReferenceEntities(pool *pgxpool.Pool) []string {
-	rows, err := pool.Query(context.Background(), "SELECT entity_value FROM reference_entities")
+// LoadReferenceEntities loads the reference streets binary keys are computed against
+func LoadReferenceEntities(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	rows, err := pool.Query(ctx, "SELECT entity_value FROM reference_entities")
 	if err != nil {
-		log.Fatalf("Query failed: %v\n", err)
+		return nil, stageError(StageReferenceEntities, err)
 	}
 	defer rows.Close()
 
 	var referenceEntities []string
 	for rows.Next() {
 		var entityValue string
-		err := rows.Scan(&entityValue)
-		if err != nil {
-			log.Fatalf("Row scan failed: %v\n", err)
+		if err := rows.Scan(&entityValue); err != nil {
+			return nil, stageError(StageReferenceEntities, err)
 		}
 		referenceEntities = append(referenceEntities, entityValue)
 	}
-	return referenceEntities
+	if err := rows.Err(); err != nil {
+		return nil, stageError(StageReferenceEntities, err)
+	}
+	return referenceEntities, nil
 }
 
 // Calculate the binary key for a given street address
@@ -100,18 +94,25 @@ func CalculateBinaryKey(referenceEntities []string, street string) string {
 	return binaryKey.String()
 }
 
-// ProcessCustomerAddresses processes customer addresses and generates binary keys
-func ProcessCustomerAddresses(pool *pgxpool.Pool, referenceEntities []string, numWorkers int, runID int) {
+// ProcessCustomerAddresses processes customer addresses and generates binary keys.
+// The first failed insert cancels the remaining work and is returned.
+func ProcessCustomerAddresses(ctx context.Context, pool *pgxpool.Pool, referenceEntities []string, numWorkers int, runID int) (err error) {
+	ctx, st := startStage(ctx, StageBinaryKeys, runID)
+	defer func() { st.end(err) }()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Query the customer_matching table with the specified run_id
-	rows, err := pool.Query(context.Background(), "SELECT customer_id, street FROM customer_matching WHERE run_id = $1", runID)
+	rows, err := pool.Query(ctx, "SELECT customer_id, COALESCE(street, '') FROM customer_matching WHERE run_id = $1 AND tenant_id = $2", runID, TenantFromContext(ctx))
 	if err != nil {
-		log.Fatalf("Query failed: %v\n", err)
+		return stageError(StageBinaryKeys, err)
 	}
 	defer rows.Close()
 
 	var wg sync.WaitGroup
 	addressCh := make(chan [2]interface{}, 1000)
 	resultCh := make(chan [2]interface{}, 1000)
+	insertErr := make(chan error, 1)
 
 	// Start worker goroutines
 	for i := 0; i < numWorkers; i++ {
@@ -122,9 +123,11 @@ func ProcessCustomerAddresses(pool *pgxpool.Pool, referenceEntities []string, nu
 				id := addr[0].(int)
 				street := addr[1].(string)
 
+				start := time.Now()
 				standardizedStreet, err := StandardizeAddress(street)
+				metrics.ObserveStage(StageStandardization, start)
 				if err != nil {
-					log.Printf("Failed to standardize address: %v\n", err)
+					slog.WarnContext(ctx, "failed to standardize address", "customer_id", id, "error_detail", err.Error())
 					continue
 				}
 				binaryKey := CalculateBinaryKey(referenceEntities, strings.ToLower(standardizedStreet))
@@ -133,41 +136,59 @@ func ProcessCustomerAddresses(pool *pgxpool.Pool, referenceEntities []string, nu
 		}()
 	}
 
-	// Insert results in batches
+	// Insert results in batches; after a failure the remaining results are drained
 	go func() {
 		var batchSize = 1000
 		var batch [][2]interface{}
+		var err error
 		for res := range resultCh {
+			if err != nil {
+				continue
+			}
 			batch = append(batch, res)
 			if len(batch) >= batchSize {
-				log.Printf("Inserting batch of size %d into customer_keys\n", len(batch))
-				InsertBatch(pool, batch, runID)
+				slog.DebugContext(ctx, "inserting binary keys", "run_id", runID, "batch", len(batch))
+				if err = InsertBatch(ctx, pool, batch, runID); err != nil {
+					cancel()
+				}
 				batch = batch[:0] // reset batch
 			}
 		}
-		if len(batch) > 0 {
-			log.Printf("Inserting final batch of size %d into customer_keys\n", len(batch))
-			InsertBatch(pool, batch, runID)
+		if err == nil && len(batch) > 0 {
+			slog.DebugContext(ctx, "inserting final binary keys", "run_id", runID, "batch", len(batch))
+			err = InsertBatch(ctx, pool, batch, runID)
 		}
+		insertErr <- err
 	}()
 
 	// Enqueue addresses for processing
+	var scanErr error
+	enqueued := 0
 	for rows.Next() {
 		var id int
 		var street string
-		err := rows.Scan(&id, &street)
-		if err != nil {
-			log.Fatalf("Row scan failed: %v\n", err)
+		if scanErr = rows.Scan(&id, &street); scanErr != nil {
+			break
 		}
 		addressCh <- [2]interface{}{id, street}
+		enqueued++
+	}
+	addRows(ctx, StageBinaryKeys, enqueued)
+	if scanErr == nil {
+		scanErr = rows.Err()
 	}
 	close(addressCh)
 	wg.Wait()
 	close(resultCh)
+
+	if err := <-insertErr; err != nil {
+		return stageError(StageBinaryKeys, err)
+	}
+	return stageError(StageBinaryKeys, scanErr)
 }
 
 // InsertBatch inserts a batch of results into the database
-func InsertBatch(pool *pgxpool.Pool, batch [][2]interface{}, runID int) {
+func InsertBatch(ctx context.Context, pool *pgxpool.Pool, batch [][2]interface{}, runID int) error {
 	batchSize := len(batch)
 	ids := make([]interface{}, batchSize)
 	keys := make([]interface{}, batchSize)
@@ -177,26 +198,25 @@ func InsertBatch(pool *pgxpool.Pool, batch [][2]interface{}, runID int) {
 		keys[i] = record[1]
 	}
 
-	log.Printf("Executing batch insert with %d records\n", batchSize)
-	_, err := pool.Exec(context.Background(),
-		"INSERT INTO customer_keys (customer_id, binary_key, run_id) SELECT UNNEST($1::int[]), UNNEST($2::text[]), $3",
-		ids, keys, runID,
+	_, err := pool.Exec(ctx,
+		"INSERT INTO customer_keys (customer_id, binary_key, run_id, tenant_id) SELECT UNNEST($1::int[]), UNNEST($2::text[]), $3, $4",
+		ids, keys, runID, TenantFromContext(ctx),
 	)
 	if err != nil {
-		log.Fatalf("Batch insert failed: %v\n", err)
+		return fmt.Errorf("batch insert failed: %w", err)
 	}
+	return nil
 }
 
 // ProcessSingleRecord processes a single record and inserts it into the database
-func ProcessSingleRecord(pool *pgxpool.Pool, req MatchRequest) error {
-	_, err := pool.Exec(context.Background(),
-		"INSERT INTO customer_matching (first_name, last_name, phone_number, street, city, state, zip_code, run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+func ProcessSingleRecord(ctx context.Context, pool *pgxpool.Pool, req MatchRequest) error {
+	_, err := pool.Exec(ctx,
+		"INSERT INTO customer_matching (first_name, last_name, phone_number, street, city, state, zip_code, run_id, tenant_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
 		strings.ToLower(req.FirstName), strings.ToLower(req.LastName), strings.ToLower(req.PhoneNumber),
-		strings.ToLower(req.Street), strings.ToLower(req.City), strings.ToLower(req.State), strings.ToLower(req.ZipCode), req.RunID)
+		strings.ToLower(req.Street), strings.ToLower(req.City), strings.ToLower(req.State), strings.ToLower(req.ZipCode), req.RunID, TenantFromContext(ctx))
 
 	if err != nil {
-		log.Printf("Failed to insert single record: %v\n", err)
-		return err
+		return stageError(StageLoadInput, err)
 	}
 
 	return nil
@@ -214,85 +234,106 @@ func join(slice []float64, sep string) string {
 	return str
 }
 
-func CreateNewRun(pool *pgxpool.Pool, description string) int {
-	var runID int
-	err := pool.QueryRow(context.Background(),
-		"INSERT INTO runs (description) VALUES ($1) RETURNING run_id",
-		description,
-	).Scan(&runID)
-	if err != nil {
-		log.Fatalf("Failed to create new run: %v\n", err)
-	}
-	return runID
+type callerKey struct{}
+
+// WithCaller returns a context that records caller as the creator of any run
+// registered with it
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
 }
 
-func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read config file: %v", err)
-	}
+// CallerFromContext returns the caller set by WithCaller, or ""
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// Querier runs statements on a pool or inside a transaction
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
 
-	var config Config
-	err = yaml.Unmarshal(data, &config)
+// CreateNewRun registers a run of the tenant of ctx and returns its id. The
+// run's created_by is the caller recorded on ctx, if any.
+func CreateNewRun(ctx context.Context, q Querier, description string) (int, error) {
+	var runID int
+	err := q.QueryRow(ctx,
+		"INSERT INTO runs (description, created_by, tenant_id) VALUES ($1, NULLIF($2, ''), $3) RETURNING run_id",
+		description, CallerFromContext(ctx), TenantFromContext(ctx),
+	).Scan(&runID)
 	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal config file: %v", err)
+		return 0, stageError(StageCreateRun, err)
 	}
-
-	return &config, nil
+	return runID, nil
 }
 
-func ClearOldCandidates(pool *pgxpool.Pool, runID int) {
+// ClearOldCandidates deletes the keys, tokens and embeddings of a run of the tenant of ctx
+func ClearOldCandidates(ctx context.Context, pool *pgxpool.Pool, runID int) error {
 	tables := []string{
 		"customer_keys",
 		"customer_tokens",
 		"customer_vector_embedding",
 	}
 	for _, table := range tables {
-		query := fmt.Sprintf("DELETE FROM %s WHERE run_id = $1", table)
-		if _, err := pool.Exec(context.Background(), query, runID); err != nil {
-			fmt.Printf("Failed to clear old candidates from %s: %v\n", table, err)
+		query := fmt.Sprintf("DELETE FROM %s WHERE run_id = $1 AND tenant_id = $2", table)
+		if _, err := pool.Exec(ctx, query, runID, TenantFromContext(ctx)); err != nil {
+			return fmt.Errorf("failed to clear old candidates from %s: %w", table, err)
 		}
 	}
+	return nil
 }
 
-// GenerateEmbeddingsPythonScript runs the Python script to generate embeddings.
-func GenerateEmbeddingsPythonScript(scriptPath string, runID int) error {
+// GenerateEmbeddingsPythonScript runs the Python script to generate embeddings
+// for the records of the tenant of ctx. The script is killed when ctx is done.
+func GenerateEmbeddingsPythonScript(ctx context.Context, scriptPath string, runID int) error {
+	return PythonEmbedder{ScriptPath: scriptPath}.Embed(ctx, runID)
+}
+
+// runEmbeddingScript runs the embedding script with the embedder's interpreter
+// and extra environment
+func runEmbeddingScript(ctx context.Context, e PythonEmbedder, runID int) error {
+	python := e.Python
+	if python == "" {
+		python = "python3"
+	}
+
 	// Ensure the script path is absolute
-	absScriptPath, err := filepath.Abs(scriptPath)
+	absScriptPath, err := filepath.Abs(e.ScriptPath)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path for script: %v", err)
+		return stageError(StageEmbeddings, fmt.Errorf("failed to get absolute path for script: %v", err))
 	}
 
 	// Set the working directory to the script's directory
 	scriptDir := filepath.Dir(absScriptPath)
 
-	cmd := exec.Command("python3", absScriptPath, strconv.Itoa(runID))
+	cmd := exec.CommandContext(ctx, python, absScriptPath, strconv.Itoa(runID), TenantFromContext(ctx))
 	cmd.Dir = scriptDir
+	if len(e.Env) > 0 {
+		cmd.Env = append(os.Environ(), e.Env...)
+	}
 
-	// Log the absolute script path and current working directory
-	log.Printf("Running Python script: %s", absScriptPath)
-	log.Printf("Set working directory to: %s", scriptDir)
+	slog.InfoContext(ctx, "running embedding script", "run_id", runID, "script", absScriptPath, "dir", scriptDir)
 
 	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return stageError(StageEmbeddings, ctx.Err())
+	}
 	if err != nil {
-		log.Printf("Error generating embeddings: error running Python script: %v, output: %s", err, string(output))
-		return fmt.Errorf("error running Python script: %v, output: %s", err, string(output))
+		slog.ErrorContext(ctx, "embedding script failed", "run_id", runID, "error", err, "error_detail", string(output))
+		return stageError(StageEmbeddings, fmt.Errorf("error running Python script: %v, output: %s", err, string(output)))
 	}
 	return nil
 }
 
-// InsertFromLoadTable inserts records from the load_table into customer_matching
-func InsertFromLoadTable(pool *pgxpool.Pool, runID int) error {
-	_, err := pool.Exec(context.Background(),
-		`INSERT INTO customer_matching (customer_id, first_name, last_name, phone_number, street, city, state, zip_code, run_id)
-		 SELECT customer_id, LOWER(first_name), LOWER(last_name), phone_number, street, LOWER(city), LOWER(state), LOWER(zip_code::TEXT), $1 AS run_id
-		 FROM batch_match`, runID)
-	return err
-}
-
-// TruncateBatchMatchTable truncates the batch_match table
-func TruncateBatchMatchTable(pool *pgxpool.Pool) error {
-	_, err := pool.Exec(context.Background(), "TRUNCATE TABLE batch_match")
-	return err
+// InsertFromLoadTable inserts records from a load table into customer_matching
+// as the input of a run of the tenant of ctx. The load table carries the
+// records' source_system and updated_at provenance too; either may be NULL.
+func InsertFromLoadTable(ctx context.Context, q Querier, loadTable string, runID int) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO customer_matching (customer_id, first_name, last_name, phone_number, street, city, state, zip_code, source_system, updated_at, run_id, tenant_id)
+		 SELECT customer_id, LOWER(first_name), LOWER(last_name), phone_number, street, LOWER(city), LOWER(state), LOWER(zip_code::TEXT), source_system, updated_at, $1 AS run_id, $2 AS tenant_id
+		 FROM `+pgx.Identifier{loadTable}.Sanitize(), runID, TenantFromContext(ctx))
+	return stageError(StageLoadInput, err)
 }
 
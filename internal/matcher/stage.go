@@ -0,0 +1,69 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/metrics"
+	"github.com/TFMV/AddressMatchPro/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stage times one pipeline stage of a run in the stage metrics and a trace span
+type stage struct {
+	name  string
+	start time.Time
+	span  trace.Span
+}
+
+// startStage starts the span of a pipeline stage of runID. Queries made with
+// the returned context are traced as its children.
+func startStage(ctx context.Context, name string, runID int) (context.Context, *stage) {
+	ctx, span := tracing.Start(ctx, name, trace.WithAttributes(
+		attribute.Int("amp.run_id", runID),
+		attribute.String("amp.tenant", TenantFromContext(ctx)),
+	))
+	return ctx, &stage{name: name, start: time.Now(), span: span}
+}
+
+// end observes the stage duration and ends its span with err
+func (s *stage) end(err error) {
+	metrics.ObserveStage(s.name, s.start)
+	tracing.End(s.span, err)
+}
+
+// addRows counts the records a stage processed on its metric and span
+func addRows(ctx context.Context, stage string, n int) {
+	metrics.AddRows(stage, n)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("amp.rows", n))
+}
@@ -0,0 +1,217 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// match.sql is the nearest-neighbour query shared by the vector strategy and
+// deduplication. It is rendered with the condition selecting the input records
+// and any further conditions on their candidates.
+//
+//go:embed match.sql
+var vectorQueryTemplate string
+
+var vectorQuery = template.Must(template.New("match.sql").Parse(vectorQueryTemplate))
+
+// vectorQueryFilters fill in match.sql. Parameters $2, $3 and $4 are always
+// the max distance, the candidate limit and the tenant.
+type vectorQueryFilters struct {
+	Input      string
+	Candidates []string
+}
+
+// renderVectorQuery renders match.sql with the given filters
+func renderVectorQuery(f vectorQueryFilters) string {
+	var b strings.Builder
+	if err := vectorQuery.Execute(&b, f); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
+
+var vectorMatchSQL = renderVectorQuery(vectorQueryFilters{Input: "input.run_id = $1"})
+
+//go:embed match_tfidf.sql
+var tfidfMatchSQL string
+
+//go:embed match_bin_key.sql
+var binKeyMatchSQL string
+
+// DefaultStrategy is used when a request does not name a strategy
+const DefaultStrategy = "vector"
+
+// geographyBlock is the blocking condition shared by the registered strategies
+const geographyBlock = "geography: same state or zip code, and same zip code, city or phone number"
+
+// binKeyBlocking notes a shared street binary key
+func binKeyBlocking(c Candidate) []string {
+	if c.BinKeyMatch {
+		return []string{"binary_key: shared street binary key"}
+	}
+	return nil
+}
+
+// Strategy is a named, versioned candidate query. Every strategy returns the
+// same columns so that its rows can be scanned and scored the same way.
+type Strategy struct {
+	Name        string
+	Version     int
+	Description string
+	SQL         string
+	// Args builds the query parameters for a run of a tenant. The query must
+	// only read records of that tenant and end with its input_tenant_id and
+	// candidate_tenant_id columns.
+	Args func(runID int, tenant string, opts MatchOptions) []interface{}
+	// Blocking describes the path that produced a candidate, for explanations
+	Blocking func(c Candidate, opts MatchOptions) []string
+	// Profile weights the features the query returns; the zero value uses
+	// PersonScoringProfile. A query without a vector distance leaves
+	// Profile.Similarity at zero.
+	Profile ScoringProfile
+}
+
+// ScoringProfile returns the profile candidates of the strategy are scored
+// with: the configured one, or else the strategy's own. A strategy without a
+// vector distance drops its weight from a configured profile too.
+func (s Strategy) ScoringProfile(opts MatchOptions) ScoringProfile {
+	own := s.Profile
+	if own == (ScoringProfile{}) {
+		own = PersonScoringProfile()
+	}
+	if opts.Profile == nil {
+		return own
+	}
+	if own.Similarity == 0 {
+		return opts.Profile.WithoutSimilarity()
+	}
+	return *opts.Profile
+}
+
+// StatementName is the name the query is prepared under on each connection
+func (s Strategy) StatementName() string {
+	return fmt.Sprintf("match_%s_v%d", s.Name, s.Version)
+}
+
+var strategies = make(map[string]Strategy)
+
+func init() {
+	for _, s := range []Strategy{
+		{
+			Name:        "vector",
+			Version:     3,
+			Description: "Nearest candidates by vector embedding distance within the same geography, scored with TF-IDF and binary keys",
+			SQL:         vectorMatchSQL,
+			Args: func(runID int, tenant string, opts MatchOptions) []interface{} {
+				return []interface{}{runID, opts.MaxDistance, opts.CandidateLimit, tenant}
+			},
+			Blocking: func(c Candidate, opts MatchOptions) []string {
+				return append([]string{
+					fmt.Sprintf("vector_distance: %.4f <= max_distance %.4f", c.Similarity, opts.MaxDistance),
+					geographyBlock,
+				}, binKeyBlocking(c)...)
+			},
+		},
+		{
+			Name:        "tfidf",
+			Version:     4,
+			Description: "Candidates sharing weighted name and street trigrams above a minimum TF-IDF score",
+			SQL:         tfidfMatchSQL,
+			Args: func(runID int, tenant string, opts MatchOptions) []interface{} {
+				return []interface{}{runID, opts.MinTfidfScore, opts.CandidateLimit, tenant}
+			},
+			Blocking: func(c Candidate, opts MatchOptions) []string {
+				return append([]string{
+					fmt.Sprintf("tfidf_score: %.4f >= min_tfidf_score %.4f", c.TfidfScore, opts.MinTfidfScore),
+					geographyBlock,
+				}, binKeyBlocking(c)...)
+			},
+			Profile: PersonScoringProfile().WithoutSimilarity(),
+		},
+		{
+			Name:        "bin_key",
+			Version:     3,
+			Description: "Candidates sharing the street binary key within the same geography",
+			SQL:         binKeyMatchSQL,
+			Args: func(runID int, tenant string, opts MatchOptions) []interface{} {
+				return []interface{}{runID, opts.CandidateLimit, tenant}
+			},
+			Blocking: func(c Candidate, opts MatchOptions) []string {
+				return append(binKeyBlocking(c), geographyBlock)
+			},
+			Profile: PersonScoringProfile().WithoutSimilarity(),
+		},
+	} {
+		if err := RegisterStrategy(s); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterStrategy adds a strategy to the registry
+func RegisterStrategy(s Strategy) error {
+	if s.Name == "" || s.SQL == "" || s.Args == nil {
+		return fmt.Errorf("strategy must have a name, SQL and args")
+	}
+	if _, exists := strategies[s.Name]; exists {
+		return fmt.Errorf("strategy %q is already registered", s.Name)
+	}
+	strategies[s.Name] = s
+	return nil
+}
+
+// LookupStrategy returns the named strategy, or the default for an empty name
+func LookupStrategy(name string) (Strategy, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+	s, ok := strategies[name]
+	if !ok {
+		return Strategy{}, fmt.Errorf("unknown match strategy %q", name)
+	}
+	return s, nil
+}
+
+// Strategies returns all registered strategies sorted by name
+func Strategies() []Strategy {
+	list := make([]Strategy, 0, len(strategies))
+	for _, s := range strategies {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
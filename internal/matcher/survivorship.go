@@ -0,0 +1,482 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Survivorship rules for picking a golden record field value
+const (
+	RuleMostRecent      = "most_recent"
+	RuleMostFrequent    = "most_frequent"
+	RuleLongest         = "longest"
+	RulePreferredSource = "preferred_source"
+)
+
+// GoldenFields are the customer_matching fields a golden record is built from
+var GoldenFields = []string{"first_name", "last_name", "phone_number", "street", "city", "state", "zip_code"}
+
+// SurvivorshipRules choose, per field, which member record supplies the value
+type SurvivorshipRules struct {
+	// Default applies to every field without an entry in Fields
+	Default string
+	Fields  map[string]string
+	// SourcePriority ranks source systems for the preferred_source rule
+	SourcePriority []string
+}
+
+// DefaultSurvivorshipRules keeps the longest non-empty value of every field
+func DefaultSurvivorshipRules() SurvivorshipRules {
+	return SurvivorshipRules{Default: RuleLongest}
+}
+
+// RuleFor returns the rule applied to a field
+func (r SurvivorshipRules) RuleFor(field string) string {
+	if rule, ok := r.Fields[field]; ok {
+		return rule
+	}
+	return r.Default
+}
+
+// Validate checks rule names, field names and the source priority
+func (r SurvivorshipRules) Validate() error {
+	known := map[string]bool{RuleMostRecent: true, RuleMostFrequent: true, RuleLongest: true, RulePreferredSource: true}
+	fields := make(map[string]bool, len(GoldenFields))
+	for _, f := range GoldenFields {
+		fields[f] = true
+	}
+
+	if !known[r.Default] {
+		return fmt.Errorf("unknown default survivorship rule %q", r.Default)
+	}
+	usesSource := r.Default == RulePreferredSource
+	for field, rule := range r.Fields {
+		if !fields[field] {
+			return fmt.Errorf("unknown survivorship field %q", field)
+		}
+		if !known[rule] {
+			return fmt.Errorf("unknown survivorship rule %q for field %s", rule, field)
+		}
+		usesSource = usesSource || rule == RulePreferredSource
+	}
+	if usesSource && len(r.SourcePriority) == 0 {
+		return fmt.Errorf("the %s rule needs a source_priority list", RulePreferredSource)
+	}
+	return nil
+}
+
+// SourceRecord is a cluster member with the provenance survivorship needs.
+// An empty SourceSystem or zero UpdatedAt means the provenance is unknown.
+type SourceRecord struct {
+	CustomerID   int
+	SourceSystem string
+	UpdatedAt    time.Time
+	Fields       map[string]string
+}
+
+// CheckProvenance returns an ErrInvalidRequest error when a member lacks the
+// updated_at a most_recent rule reads or the source_system a preferred_source
+// rule reads; without them the rules would silently pick the lowest id.
+func (r SurvivorshipRules) CheckProvenance(members []SourceRecord) error {
+	for _, field := range GoldenFields {
+		rule := r.RuleFor(field)
+		for _, m := range members {
+			switch {
+			case rule == RuleMostRecent && m.UpdatedAt.IsZero():
+				return invalidf("customer %d has no updated_at for the %s rule on %s", m.CustomerID, rule, field)
+			case rule == RulePreferredSource && m.SourceSystem == "":
+				return invalidf("customer %d has no source_system for the %s rule on %s", m.CustomerID, rule, field)
+			}
+		}
+	}
+	return nil
+}
+
+// FieldLineage records which member supplied a golden field and why
+type FieldLineage struct {
+	Field            string `json:"field"`
+	Value            string `json:"value"`
+	SourceCustomerID int    `json:"source_customer_id"`
+	Rule             string `json:"rule"`
+}
+
+// GoldenRecord is the best representative record of an entity
+type GoldenRecord struct {
+	RunID       int            `json:"run_id"`
+	EntityID    int            `json:"entity_id"`
+	FirstName   string         `json:"first_name"`
+	LastName    string         `json:"last_name"`
+	PhoneNumber string         `json:"phone_number"`
+	Street      string         `json:"street"`
+	City        string         `json:"city"`
+	State       string         `json:"state"`
+	ZipCode     string         `json:"zip_code"`
+	MemberCount int            `json:"member_count"`
+	Lineage     []FieldLineage `json:"lineage"`
+}
+
+func (g *GoldenRecord) set(field, value string) {
+	switch field {
+	case "first_name":
+		g.FirstName = value
+	case "last_name":
+		g.LastName = value
+	case "phone_number":
+		g.PhoneNumber = value
+	case "street":
+		g.Street = value
+	case "city":
+		g.City = value
+	case "state":
+		g.State = value
+	case "zip_code":
+		g.ZipCode = value
+	}
+}
+
+// BuildGoldenRecord applies the survivorship rules to a cluster's members.
+// Empty values never survive; ties fall back to the most recent record and
+// then the lowest customer id, so the result is deterministic.
+func BuildGoldenRecord(entityID int, members []SourceRecord, rules SurvivorshipRules) GoldenRecord {
+	golden := GoldenRecord{EntityID: entityID, MemberCount: len(members), Lineage: []FieldLineage{}}
+
+	sourceRank := make(map[string]int, len(rules.SourcePriority))
+	for i, source := range rules.SourcePriority {
+		sourceRank[strings.ToLower(source)] = i
+	}
+	rankOf := func(source string) int {
+		if rank, ok := sourceRank[strings.ToLower(source)]; ok {
+			return rank
+		}
+		return len(sourceRank)
+	}
+
+	for _, field := range GoldenFields {
+		rule := rules.RuleFor(field)
+
+		var values []SourceRecord
+		frequency := make(map[string]int)
+		for _, m := range members {
+			v := strings.TrimSpace(m.Fields[field])
+			if v == "" {
+				continue
+			}
+			values = append(values, m)
+			frequency[v]++
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		sort.SliceStable(values, func(i, j int) bool {
+			a, b := values[i], values[j]
+			va, vb := strings.TrimSpace(a.Fields[field]), strings.TrimSpace(b.Fields[field])
+			switch rule {
+			case RuleMostFrequent:
+				if frequency[va] != frequency[vb] {
+					return frequency[va] > frequency[vb]
+				}
+			case RuleLongest:
+				if len(va) != len(vb) {
+					return len(va) > len(vb)
+				}
+			case RulePreferredSource:
+				if rankOf(a.SourceSystem) != rankOf(b.SourceSystem) {
+					return rankOf(a.SourceSystem) < rankOf(b.SourceSystem)
+				}
+			}
+			if !a.UpdatedAt.Equal(b.UpdatedAt) {
+				return a.UpdatedAt.After(b.UpdatedAt)
+			}
+			return a.CustomerID < b.CustomerID
+		})
+
+		winner := values[0]
+		value := strings.TrimSpace(winner.Fields[field])
+		golden.set(field, value)
+		golden.Lineage = append(golden.Lineage, FieldLineage{
+			Field:            field,
+			Value:            value,
+			SourceCustomerID: winner.CustomerID,
+			Rule:             rule,
+		})
+	}
+
+	return golden
+}
+
+// GoldenRecordRequest selects the clusters to build golden records for: an
+// explicit grouping of run 0 customer ids, or an entity resolution run (0 for
+// the latest) when no groups are given
+type GoldenRecordRequest struct {
+	EntityRunID int     `json:"entity_run_id"`
+	Groups      [][]int `json:"groups"`
+}
+
+// Validate checks that every group is non-empty and that no customer id is
+// listed twice, within a group or across groups. Each group's entity id is its
+// lowest member id, so overlapping groups would share an entity id.
+func (r GoldenRecordRequest) Validate() error {
+	group := make(map[int]int)
+	for i, members := range r.Groups {
+		if len(members) == 0 {
+			return invalidf("group %d is empty", i)
+		}
+		for _, id := range members {
+			if j, ok := group[id]; ok {
+				if j == i {
+					return invalidf("customer %d is listed twice in group %d", id, i)
+				}
+				return invalidf("customer %d is in groups %d and %d", id, j, i)
+			}
+			group[id] = i
+		}
+	}
+	return nil
+}
+
+// GoldenRecordRun summarises a stored golden record run
+type GoldenRecordRun struct {
+	RunID         int `json:"run_id"`
+	GoldenRecords int `json:"golden_records"`
+}
+
+// BuildGoldenRecords builds and stores a golden record, with field lineage,
+// for every selected cluster under a new run
+func BuildGoldenRecords(ctx context.Context, pool *pgxpool.Pool, req GoldenRecordRequest, rules SurvivorshipRules) (GoldenRecordRun, error) {
+	if err := rules.Validate(); err != nil {
+		return GoldenRecordRun{}, err
+	}
+
+	clusters, err := goldenClusters(ctx, pool, req)
+	if err != nil {
+		return GoldenRecordRun{}, err
+	}
+	if len(clusters) == 0 {
+		return GoldenRecordRun{}, invalidf("no clusters to build golden records for")
+	}
+
+	var ids []int
+	for _, c := range clusters {
+		ids = append(ids, c.Members...)
+	}
+	records, err := loadSourceRecords(ctx, pool, ids)
+	if err != nil {
+		return GoldenRecordRun{}, err
+	}
+
+	// The run is created in the transaction that stores its records, so a
+	// failed build leaves no empty run behind
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return GoldenRecordRun{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	runID, err := CreateNewRun(ctx, tx, "Golden Records")
+	if err != nil {
+		return GoldenRecordRun{}, err
+	}
+
+	var goldenRows, lineageRows [][]interface{}
+	for _, c := range clusters {
+		var members []SourceRecord
+		for _, id := range c.Members {
+			if r, ok := records[id]; ok {
+				members = append(members, r)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		if err := rules.CheckProvenance(members); err != nil {
+			return GoldenRecordRun{}, err
+		}
+		golden := BuildGoldenRecord(c.EntityID, members, rules)
+		goldenRows = append(goldenRows, []interface{}{runID, golden.EntityID, golden.FirstName, golden.LastName,
+			golden.PhoneNumber, golden.Street, golden.City, golden.State, golden.ZipCode, golden.MemberCount})
+		for _, l := range golden.Lineage {
+			lineageRows = append(lineageRows, []interface{}{runID, golden.EntityID, l.Field, l.Value, l.SourceCustomerID, l.Rule})
+		}
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"golden_records"},
+		[]string{"run_id", "entity_id", "first_name", "last_name", "phone_number", "street", "city", "state", "zip_code", "member_count"},
+		pgx.CopyFromRows(goldenRows)); err != nil {
+		return GoldenRecordRun{}, fmt.Errorf("failed to store golden records: %v", err)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"golden_record_lineage"},
+		[]string{"run_id", "entity_id", "field_name", "field_value", "source_customer_id", "rule"},
+		pgx.CopyFromRows(lineageRows)); err != nil {
+		return GoldenRecordRun{}, fmt.Errorf("failed to store golden record lineage: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return GoldenRecordRun{}, err
+	}
+
+	slog.InfoContext(ctx, "built golden records", "run_id", runID, "records", len(goldenRows))
+	return GoldenRecordRun{RunID: runID, GoldenRecords: len(goldenRows)}, nil
+}
+
+// goldenClusters resolves the request into clusters of run 0 customer ids
+func goldenClusters(ctx context.Context, pool *pgxpool.Pool, req GoldenRecordRequest) ([]Cluster, error) {
+	if len(req.Groups) > 0 {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+		var clusters []Cluster
+		for _, group := range req.Groups {
+			clusters = append(clusters, newCluster(group))
+		}
+		return clusters, nil
+	}
+
+	runID := req.EntityRunID
+	if runID == 0 {
+		if err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(ec.run_id), 0) FROM entity_clusters ec JOIN runs r ON (r.run_id = ec.run_id) WHERE r.tenant_id = $1", TenantFromContext(ctx)).Scan(&runID); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkRunTenant(ctx, pool, runID); err != nil {
+		return nil, fmt.Errorf("entity resolution run %d not found: %w", runID, err)
+	}
+	rows, err := pool.Query(ctx, "SELECT entity_id, customer_id FROM entity_clusters WHERE run_id = $1 ORDER BY entity_id, customer_id", runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clusters []Cluster
+	for rows.Next() {
+		var entityID, customerID int
+		if err := rows.Scan(&entityID, &customerID); err != nil {
+			return nil, err
+		}
+		if len(clusters) == 0 || clusters[len(clusters)-1].EntityID != entityID {
+			clusters = append(clusters, Cluster{EntityID: entityID})
+		}
+		clusters[len(clusters)-1].Members = append(clusters[len(clusters)-1].Members, customerID)
+	}
+	return clusters, rows.Err()
+}
+
+// loadSourceRecords reads the run 0 records with their provenance
+func loadSourceRecords(ctx context.Context, pool *pgxpool.Pool, ids []int) (map[int]SourceRecord, error) {
+	rows, err := pool.Query(ctx,
+		`SELECT customer_id, COALESCE(source_system, ''), updated_at,
+		        COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(phone_number, ''), COALESCE(street, ''),
+		        COALESCE(city, ''), COALESCE(state, ''), COALESCE(zip_code, '')
+		 FROM customer_matching WHERE run_id = 0 AND tenant_id = $2 AND customer_id = ANY($1)`, ids, TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[int]SourceRecord, len(ids))
+	for rows.Next() {
+		var r SourceRecord
+		var updatedAt *time.Time
+		values := make([]string, len(GoldenFields))
+		if err := rows.Scan(&r.CustomerID, &r.SourceSystem, &updatedAt,
+			&values[0], &values[1], &values[2], &values[3], &values[4], &values[5], &values[6]); err != nil {
+			return nil, err
+		}
+		if updatedAt != nil {
+			r.UpdatedAt = *updatedAt
+		}
+		r.Fields = make(map[string]string, len(GoldenFields))
+		for i, field := range GoldenFields {
+			r.Fields[field] = values[i]
+		}
+		records[r.CustomerID] = r
+	}
+	return records, rows.Err()
+}
+
+// LoadGoldenRecord returns a stored golden record with its lineage. A runID
+// of 0 selects the tenant's latest golden record run.
+func LoadGoldenRecord(ctx context.Context, pool *pgxpool.Pool, runID int, entityID int) (GoldenRecord, error) {
+	if runID == 0 {
+		if err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(gr.run_id), 0) FROM golden_records gr JOIN runs r ON (r.run_id = gr.run_id) WHERE r.tenant_id = $1", TenantFromContext(ctx)).Scan(&runID); err != nil {
+			return GoldenRecord{}, err
+		}
+	}
+	if err := checkRunTenant(ctx, pool, runID); err != nil {
+		return GoldenRecord{}, err
+	}
+
+	golden := GoldenRecord{RunID: runID, EntityID: entityID, Lineage: []FieldLineage{}}
+	err := pool.QueryRow(ctx,
+		`SELECT COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(phone_number, ''), COALESCE(street, ''),
+		        COALESCE(city, ''), COALESCE(state, ''), COALESCE(zip_code, ''), member_count
+		 FROM golden_records WHERE run_id = $1 AND entity_id = $2`, runID, entityID).Scan(
+		&golden.FirstName, &golden.LastName, &golden.PhoneNumber, &golden.Street,
+		&golden.City, &golden.State, &golden.ZipCode, &golden.MemberCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return GoldenRecord{}, fmt.Errorf("entity %d in run %d: %w", entityID, runID, ErrNotFound)
+	}
+	if err != nil {
+		return GoldenRecord{}, err
+	}
+
+	rows, err := pool.Query(ctx,
+		`SELECT field_name, COALESCE(field_value, ''), source_customer_id, rule
+		 FROM golden_record_lineage WHERE run_id = $1 AND entity_id = $2`, runID, entityID)
+	if err != nil {
+		return GoldenRecord{}, err
+	}
+	defer rows.Close()
+
+	order := make(map[string]int, len(GoldenFields))
+	for i, f := range GoldenFields {
+		order[f] = i
+	}
+	for rows.Next() {
+		var l FieldLineage
+		if err := rows.Scan(&l.Field, &l.Value, &l.SourceCustomerID, &l.Rule); err != nil {
+			return GoldenRecord{}, err
+		}
+		golden.Lineage = append(golden.Lineage, l)
+	}
+	sort.Slice(golden.Lineage, func(i, j int) bool {
+		return order[golden.Lineage[i].Field] < order[golden.Lineage[j].Field]
+	})
+	return golden, rows.Err()
+}
@@ -0,0 +1,85 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultTenant owns the records of callers that name no tenant, and every
+// record stored before tenants were introduced
+const DefaultTenant = "default"
+
+// ErrCrossTenant is returned when a query yields a record of another tenant
+var ErrCrossTenant = errors.New("record belongs to another tenant")
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose queries are scoped to tenant. Every
+// record a run reads or writes, including its candidate space, belongs to
+// the tenant of the context it is given.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant, or DefaultTenant
+func TenantFromContext(ctx context.Context) string {
+	if tenant, _ := ctx.Value(tenantKey{}).(string); tenant != "" {
+		return tenant
+	}
+	return DefaultTenant
+}
+
+// CheckTenant guards against candidate queries that leak records across
+// tenants: it fails unless both records of the pair belong to tenant
+func CheckTenant(tenant string, c Candidate) error {
+	if c.InputTenantID != tenant || c.CandidateTenantID != tenant {
+		return fmt.Errorf("%w: pair %d/%d of tenants %q/%q returned to tenant %q", ErrCrossTenant,
+			c.InputCustomerID, c.CandidateCustomerID, c.InputTenantID, c.CandidateTenantID, tenant)
+	}
+	return nil
+}
+
+// checkRunTenant returns ErrNotFound unless the run belongs to the tenant of ctx
+func checkRunTenant(ctx context.Context, pool *pgxpool.Pool, runID int) error {
+	var exists bool
+	err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM runs WHERE run_id = $1 AND tenant_id = $2)", runID, TenantFromContext(ctx)).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("run %d: %w", runID, ErrNotFound)
+	}
+	return nil
+}
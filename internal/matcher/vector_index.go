@@ -0,0 +1,296 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// vectorIndexName is the ANN index on the candidate space partition
+	vectorIndexName = "idx_customer_vector_embedding_run_0_ann"
+
+	IndexMethodNone    = "none"
+	IndexMethodHNSW    = "hnsw"
+	IndexMethodIVFFlat = "ivfflat"
+)
+
+// IndexOptions describes the pgvector ANN index built on the run 0 embeddings
+type IndexOptions struct {
+	Method string
+	// HNSW build parameters
+	M              int
+	EfConstruction int
+	// IVFFlat build parameter
+	Lists int
+}
+
+// DefaultIndexOptions returns pgvector's documented defaults for an HNSW index
+func DefaultIndexOptions() IndexOptions {
+	return IndexOptions{
+		Method:         IndexMethodHNSW,
+		M:              16,
+		EfConstruction: 64,
+		Lists:          100,
+	}
+}
+
+// Validate checks the index method and its build parameters
+func (o IndexOptions) Validate() error {
+	switch o.Method {
+	case IndexMethodNone:
+		return nil
+	case IndexMethodHNSW:
+		if o.M < 2 || o.EfConstruction < 2*o.M {
+			return fmt.Errorf("hnsw needs m >= 2 and ef_construction >= 2*m, got m=%d ef_construction=%d", o.M, o.EfConstruction)
+		}
+	case IndexMethodIVFFlat:
+		if o.Lists < 1 {
+			return fmt.Errorf("ivfflat needs lists >= 1, got %d", o.Lists)
+		}
+	default:
+		return fmt.Errorf("unknown vector index method %q", o.Method)
+	}
+	return nil
+}
+
+// BuildVectorIndex drops and rebuilds the ANN index on the run 0 partition of
+// customer_vector_embedding. It should run after the run 0 embeddings are written.
+func BuildVectorIndex(ctx context.Context, pool *pgxpool.Pool, opts IndexOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := pool.Exec(ctx, "DROP INDEX IF EXISTS "+vectorIndexName); err != nil {
+		return fmt.Errorf("failed to drop vector index: %v", err)
+	}
+
+	var create string
+	switch opts.Method {
+	case IndexMethodNone:
+		slog.InfoContext(ctx, "vector index disabled, matching will scan run 0 embeddings")
+		return nil
+	case IndexMethodHNSW:
+		create = fmt.Sprintf("CREATE INDEX %s ON customer_vector_embedding_run_0 USING hnsw (vector_embedding vector_cosine_ops) WITH (m = %d, ef_construction = %d)",
+			vectorIndexName, opts.M, opts.EfConstruction)
+	case IndexMethodIVFFlat:
+		create = fmt.Sprintf("CREATE INDEX %s ON customer_vector_embedding_run_0 USING ivfflat (vector_embedding vector_cosine_ops) WITH (lists = %d)",
+			vectorIndexName, opts.Lists)
+	}
+
+	slog.InfoContext(ctx, "building vector index", "method", opts.Method, "table", "customer_vector_embedding_run_0")
+	if _, err := pool.Exec(ctx, create); err != nil {
+		return fmt.Errorf("failed to build vector index: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "ANALYZE customer_vector_embedding_run_0"); err != nil {
+		return fmt.Errorf("failed to analyze customer_vector_embedding_run_0: %v", err)
+	}
+	return nil
+}
+
+// applySearchSettings sets the per-query ANN settings for the current
+// transaction. Candidate queries filter the index scan by tenant and
+// geography, so scans are iterative: an HNSW scan keeps reading the index
+// until enough rows pass the filters or max_scan_tuples are visited, and an
+// IVFFlat scan keeps probing lists until it has enough rows. This needs
+// pgvector 0.8 or later.
+func applySearchSettings(ctx context.Context, tx pgx.Tx, opts MatchOptions) error {
+	names := []string{"hnsw.iterative_scan", "ivfflat.iterative_scan"}
+	values := []string{"strict_order", "relaxed_order"}
+	if opts.EfSearch > 0 {
+		names = append(names, "hnsw.ef_search")
+		values = append(values, fmt.Sprint(opts.EfSearch))
+	}
+	if opts.MaxScanTuples > 0 {
+		names = append(names, "hnsw.max_scan_tuples")
+		values = append(values, fmt.Sprint(opts.MaxScanTuples))
+	}
+	if opts.Probes > 0 {
+		names = append(names, "ivfflat.probes")
+		values = append(values, fmt.Sprint(opts.Probes))
+	}
+	if _, err := tx.Exec(ctx, "SELECT set_config(name, value, true) FROM unnest($1::TEXT[], $2::TEXT[]) AS s(name, value)", names, values); err != nil {
+		return fmt.Errorf("failed to apply vector search settings %v: %v", names, err)
+	}
+	return nil
+}
+
+// IndexReport summarises recall and latency of the ANN index for one search setting
+type IndexReport struct {
+	Method       string
+	Setting      int
+	Samples      int
+	K            int
+	Recall       float64
+	ANNLatency   time.Duration
+	ANNP95       time.Duration
+	ExactLatency time.Duration
+	ExactP95     time.Duration
+}
+
+// evaluationSQL is the vector candidate query of matching, with its tenant and
+// geography blocking, for one sampled run 0 record against the rest of run 0.
+// The distance cap is left to the caller so that recall covers every neighbour.
+var evaluationSQL = "SELECT candidate_customer_id FROM (" + strings.TrimSuffix(strings.TrimSpace(renderVectorQuery(vectorQueryFilters{
+	Input:      "input.run_id = 0 AND input.customer_id = $1",
+	Candidates: []string{"candidate_vec.customer_id <> input.customer_id"},
+})), ";") + ") evaluated"
+
+// EvaluateVectorIndex compares the ANN index against an exact scan for the
+// candidate query matching runs, filtered by tenant and geography, over a
+// random sample of the tenant's run 0 records. settings are ef_search values
+// for HNSW or probes for IVFFlat; one report is returned per setting. Recall
+// is averaged over the samples that have candidates in the exact scan.
+func EvaluateVectorIndex(ctx context.Context, pool *pgxpool.Pool, method string, settings []int, sampleSize int, k int) ([]IndexReport, error) {
+	rows, err := pool.Query(ctx, "SELECT customer_id FROM customer_vector_embedding WHERE run_id = 0 AND tenant_id = $2 ORDER BY random() LIMIT $1", sampleSize, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample embeddings: %v", err)
+	}
+	samples, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample embeddings: %v", err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no run 0 embeddings to evaluate")
+	}
+
+	// Ground truth from a brute-force scan
+	exact := make([]map[int]bool, len(samples))
+	exactTimes := make([]time.Duration, len(samples))
+	judged := 0
+	for i, id := range samples {
+		ids, elapsed, err := nearestRun0(ctx, pool, id, k, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, "SET LOCAL enable_indexscan = off")
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		exact[i] = make(map[int]bool, len(ids))
+		for _, id := range ids {
+			exact[i][id] = true
+		}
+		if len(ids) > 0 {
+			judged++
+		}
+		exactTimes[i] = elapsed
+	}
+
+	var reports []IndexReport
+	for _, setting := range settings {
+		opts := MatchOptions{EfSearch: setting}
+		if method == IndexMethodIVFFlat {
+			opts = MatchOptions{Probes: setting}
+		}
+		annTimes := make([]time.Duration, len(samples))
+		var recall float64
+		for i, id := range samples {
+			ids, elapsed, err := nearestRun0(ctx, pool, id, k, func(tx pgx.Tx) error {
+				return applySearchSettings(ctx, tx, opts)
+			})
+			if err != nil {
+				return nil, err
+			}
+			hits := 0
+			for _, id := range ids {
+				if exact[i][id] {
+					hits++
+				}
+			}
+			if len(exact[i]) > 0 {
+				recall += float64(hits) / float64(len(exact[i]))
+			}
+			annTimes[i] = elapsed
+		}
+
+		report := IndexReport{
+			Method:  method,
+			Setting: setting,
+			Samples: len(samples),
+			K:       k,
+		}
+		if judged > 0 {
+			report.Recall = recall / float64(judged)
+		}
+		report.ANNLatency, report.ANNP95 = latencyStats(annTimes)
+		report.ExactLatency, report.ExactP95 = latencyStats(exactTimes)
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// nearestRun0 runs the evaluation query for a run 0 record with no distance cap
+func nearestRun0(ctx context.Context, pool *pgxpool.Pool, customerID int, k int, setup func(pgx.Tx) error) ([]int, time.Duration, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setup(tx); err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	rows, err := tx.Query(ctx, evaluationSQL, customerID, 2.0, k, TenantFromContext(ctx))
+	if err != nil {
+		return nil, 0, fmt.Errorf("nearest neighbour query failed: %v", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, 0, fmt.Errorf("nearest neighbour query failed: %v", err)
+	}
+	return ids, time.Since(start), nil
+}
+
+// latencyStats returns the mean and 95th percentile of the durations
+func latencyStats(times []time.Duration) (time.Duration, time.Duration) {
+	if len(times) == 0 {
+		return 0, 0
+	}
+	sorted := append([]time.Duration(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, t := range sorted {
+		total += t
+	}
+	p95 := sorted[int(float64(len(sorted)-1)*0.95)]
+	return total / time.Duration(len(sorted)), p95
+}
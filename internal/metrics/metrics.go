@@ -0,0 +1,164 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+// Package metrics holds the Prometheus metrics of the service. They are
+// registered on Registry, which also carries the Go runtime and process
+// collectors, and served by Handler.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "amp"
+
+// Registry holds every metric of the service
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts API requests by route and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "API requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes API request latencies by route and status. The
+	// buckets reach the 15 minute write timeout so batch matches are resolved.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "API request latency by method, route and status.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 900},
+	}, []string{"method", "route", "status"})
+
+	// StageDuration observes the time spent in each pipeline stage. The
+	// standardization stage is observed per record, the others per run.
+	StageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Pipeline stage duration by stage.",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 12),
+	}, []string{"stage"})
+
+	// StageRows counts the records processed by each pipeline stage
+	StageRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stage_rows_total",
+		Help:      "Records processed by pipeline stage.",
+	}, []string{"stage"})
+
+	// CandidatesPerInput observes how many candidates are returned for each
+	// input record that has any
+	CandidatesPerInput = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "candidates_per_input",
+		Help:      "Candidates returned per matched input record.",
+		Buckets:   []float64{1, 2, 3, 5, 10, 20, 50, 100},
+	})
+
+	// MatchScores observes the composite score of every returned candidate
+	MatchScores = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "match_score",
+		Help:      "Composite score of returned candidates.",
+		Buckets:   prometheus.LinearBuckets(10, 10, 10),
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		StageDuration,
+		StageRows,
+		CandidatesPerInput,
+		MatchScores,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveStage records the time since start against stage
+func ObserveStage(stage string, start time.Time) {
+	StageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// AddRows counts n records processed by stage
+func AddRows(stage string, n int) {
+	StageRows.WithLabelValues(stage).Add(float64(n))
+}
+
+// RegisterPool exports the connection pool statistics
+func RegisterPool(pool *pgxpool.Pool) error {
+	return Registry.Register(poolCollector{pool: pool})
+}
+
+var (
+	poolAcquired = prometheus.NewDesc(namespace+"_db_pool_acquired_connections", "Connections currently in use.", nil, nil)
+	poolIdle     = prometheus.NewDesc(namespace+"_db_pool_idle_connections", "Idle connections in the pool.", nil, nil)
+	poolTotal    = prometheus.NewDesc(namespace+"_db_pool_total_connections", "Connections in the pool, including those being opened.", nil, nil)
+	poolMax      = prometheus.NewDesc(namespace+"_db_pool_max_connections", "Maximum size of the pool.", nil, nil)
+	poolAcquires = prometheus.NewDesc(namespace+"_db_pool_acquires_total", "Successful connection acquires.", nil, nil)
+	poolWaits    = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", nil, nil)
+	poolWaitTime = prometheus.NewDesc(namespace+"_db_pool_acquire_wait_seconds_total", "Time spent acquiring connections.", nil, nil)
+)
+
+// poolCollector reads pgxpool statistics at scrape time
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{poolAcquired, poolIdle, poolTotal, poolMax, poolAcquires, poolWaits, poolWaitTime} {
+		ch <- d
+	}
+}
+
+func (c poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotal, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMax, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolWaits, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolWaitTime, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
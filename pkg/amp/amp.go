@@ -0,0 +1,277 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+// Package amp is the public Go API of AddressMatchPro. A Matcher runs the same
+// pipeline as the HTTP server in-process: records are standardized, keyed,
+// tokenized and embedded, then matched against the candidate space (run 0).
+package amp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Errors matched by the failures of Matcher calls
+var (
+	// ErrInvalidRequest is matched by errors that reject a call's input
+	ErrInvalidRequest = matcher.ErrInvalidRequest
+	// ErrNotFound is matched when a run or record does not exist for the tenant
+	ErrNotFound = matcher.ErrNotFound
+)
+
+// DefaultTenant owns the records of contexts that name no tenant
+const DefaultTenant = matcher.DefaultTenant
+
+// WithTenant scopes every Matcher call made with the returned context to
+// tenant: records are written to and matched against that tenant's
+// candidate space only.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return matcher.WithTenant(ctx, tenant)
+}
+
+// DefaultMatchOptions returns the thresholds used when Options.Match leaves them unset
+func DefaultMatchOptions() MatchOptions {
+	return fromMatchOptions(matcher.DefaultMatchOptions())
+}
+
+// Ptr returns a pointer to v, for setting MatchOverrides fields
+func Ptr[T any](v T) *T {
+	return matcher.Ptr(v)
+}
+
+// PersonScoringProfile weights name and address together to identify one person
+func PersonScoringProfile() ScoringProfile {
+	return ScoringProfile(matcher.PersonScoringProfile())
+}
+
+// HouseholdScoringProfile weights only the address features
+func HouseholdScoringProfile() ScoringProfile {
+	return ScoringProfile(matcher.HouseholdScoringProfile())
+}
+
+// Record is a customer record to match or to add to the candidate space.
+// CustomerID is required for candidate records; for inputs it is kept when
+// every record of a batch has one and assigned otherwise. SourceSystem and
+// UpdatedAt feed the most_recent and preferred_source survivorship rules.
+type Record struct {
+	CustomerID   int        `json:"customer_id"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	PhoneNumber  string     `json:"phone_number"`
+	Street       string     `json:"street"`
+	City         string     `json:"city"`
+	State        string     `json:"state"`
+	ZipCode      string     `json:"zip_code"`
+	SourceSystem string     `json:"source_system,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// Options configures a Matcher
+type Options struct {
+	// Pool is a connection pool to a database migrated with pkg/db
+	Pool *pgxpool.Pool
+	// Embedder generates vector embeddings; required to build or match records
+	Embedder Embedder
+	// Profile weights the score features; nil uses the strategy's profile
+	Profile *ScoringProfile
+	// Match overrides the default matching thresholds
+	Match MatchOverrides
+	// Index configures the ANN index built by BuildCandidateSpace
+	Index IndexOptions
+	// Workers is the number of binary key workers per run (default 10)
+	Workers int
+}
+
+// Matcher matches records against the candidate space. It is safe for
+// concurrent use.
+type Matcher struct {
+	pool     *pgxpool.Pool
+	embedder Embedder
+	defaults matcher.MatchOptions
+	index    matcher.IndexOptions
+	workers  int
+}
+
+// New validates the options and returns a Matcher
+func New(opts Options) (*Matcher, error) {
+	if opts.Pool == nil {
+		return nil, fmt.Errorf("amp: a connection pool is required")
+	}
+	if opts.Embedder == nil {
+		return nil, fmt.Errorf("amp: an embedder is required")
+	}
+
+	defaults := matcher.DefaultMatchOptions().Merge(opts.Match.toMatcher())
+	if opts.Profile != nil {
+		defaults.Profile = (*matcher.ScoringProfile)(opts.Profile)
+	}
+	if err := defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("amp: %w", err)
+	}
+
+	index := matcher.DefaultIndexOptions()
+	if opts.Index.Method != "" {
+		index = matcher.IndexOptions(opts.Index)
+	}
+	if err := index.Validate(); err != nil {
+		return nil, fmt.Errorf("amp: %w", err)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 10
+	}
+
+	return &Matcher{pool: opts.Pool, embedder: opts.Embedder, defaults: defaults, index: index, workers: workers}, nil
+}
+
+// Defaults returns the thresholds applied where a call leaves them unset
+func (m *Matcher) Defaults() MatchOptions {
+	return fromMatchOptions(m.defaults)
+}
+
+// Standardize returns the standardized form of a street address, as used for
+// binary keys and TF-IDF tokens
+func (m *Matcher) Standardize(street string) (string, error) {
+	return matcher.StandardizeAddress(street)
+}
+
+// BuildCandidateSpace replaces the candidate space with records, when given,
+// then rebuilds its keys, tokens, embeddings and ANN index. Pass nil to
+// rebuild from the records already stored.
+func (m *Matcher) BuildCandidateSpace(ctx context.Context, records []Record) error {
+	if records != nil {
+		if err := matcher.ReplaceCandidateRecords(ctx, m.pool, inputRecords(records)); err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(matcher.RebuildCandidateSpace(ctx, m.pool, m.workers, m.embedder, m.index))
+}
+
+// MatchOne matches a single record. Nil fields of opts keep the Matcher's thresholds.
+func (m *Matcher) MatchOne(ctx context.Context, record Record, opts MatchOverrides) (MatchResult, error) {
+	results, err := m.match(ctx, "Single Record Matching", []Record{record}, opts)
+	if err != nil {
+		return MatchResult{}, err
+	}
+	if len(results) != 1 {
+		return MatchResult{}, fmt.Errorf("amp: expected one result, got %d", len(results))
+	}
+	return results[0], nil
+}
+
+// MatchBatch matches records as one run and returns one result per record,
+// ordered by customer id. Nil fields of opts keep the Matcher's thresholds.
+func (m *Matcher) MatchBatch(ctx context.Context, records []Record, opts MatchOverrides) ([]MatchResult, error) {
+	if len(records) == 0 {
+		return []MatchResult{}, nil
+	}
+	return m.match(ctx, "Batch Record Matching", records, opts)
+}
+
+// FindDuplicates returns one page of duplicate pairs within the candidate space
+func (m *Matcher) FindDuplicates(ctx context.Context, req DuplicateRequest) (DuplicatePage, error) {
+	dreq := matcher.DuplicateRequest(req)
+	opts := dreq.Options(m.defaults)
+	if err := opts.Validate(); err != nil {
+		return DuplicatePage{}, err
+	}
+	page, err := matcher.FindDuplicates(ctx, m.pool, dreq, opts)
+	if err != nil {
+		return DuplicatePage{}, wrapError(err)
+	}
+	return DuplicatePage{Pairs: fromCandidates(page.Pairs), NextAfter: page.NextAfter, HasMore: page.HasMore}, nil
+}
+
+// match runs the pipeline for records under a new run and converts its results
+func (m *Matcher) match(ctx context.Context, description string, records []Record, overrides MatchOverrides) ([]MatchResult, error) {
+	results, err := m.run(ctx, description, records, overrides)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return fromMatchResults(results), nil
+}
+
+// run runs the pipeline for records under a new run. Every scored candidate
+// is stored in match_results like those of the HTTP API.
+func (m *Matcher) run(ctx context.Context, description string, records []Record, overrides MatchOverrides) ([]matcher.MatchResult, error) {
+	opts := m.defaults.Merge(overrides.toMatcher())
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	runID, err := matcher.CreateNewRun(ctx, m.pool, description)
+	if err != nil {
+		return nil, err
+	}
+	if err := matcher.InsertRunRecords(ctx, m.pool, runID, inputRecords(records)); err != nil {
+		return nil, err
+	}
+	if err := matcher.PrepareRun(ctx, m.pool, runID, m.workers, m.embedder); err != nil {
+		return nil, err
+	}
+
+	scored, err := matcher.FindPotentialMatches(ctx, m.pool, runID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := matcher.StoreMatchResults(ctx, m.pool, runID, opts.Strategy, scored); err != nil {
+		return nil, err
+	}
+	candidates := matcher.ReturnedCandidates(scored)
+
+	inputs, err := matcher.LoadRunInputs(ctx, m.pool, runID)
+	if err != nil {
+		return nil, err
+	}
+	return matcher.GroupByInput(inputs, candidates), nil
+}
+
+func inputRecords(records []Record) []matcher.InputRecord {
+	inputs := make([]matcher.InputRecord, len(records))
+	for i, r := range records {
+		inputs[i] = matcher.InputRecord{
+			CustomerID:   r.CustomerID,
+			FirstName:    r.FirstName,
+			LastName:     r.LastName,
+			PhoneNumber:  r.PhoneNumber,
+			Street:       r.Street,
+			City:         r.City,
+			State:        r.State,
+			ZipCode:      r.ZipCode,
+			SourceSystem: r.SourceSystem,
+			UpdatedAt:    r.UpdatedAt,
+		}
+	}
+	return inputs
+}
@@ -0,0 +1,393 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package amp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+// MatchOptions are the matching thresholds
+type MatchOptions struct {
+	// Strategy selects the candidate query: vector, tfidf or bin_key
+	Strategy string
+	TopN     int
+	// MaxDistance is the largest cosine distance a vector candidate may have
+	MaxDistance float64
+	// CandidateLimit caps the candidates pulled per input record by the query
+	CandidateLimit int
+	// MinScore drops scored candidates below this composite score
+	MinScore      float64
+	MinTfidfScore float64
+	// EfSearch and Probes tune the HNSW and IVFFlat index scans for the query
+	EfSearch int
+	Probes   int
+	// MaxScanTuples caps the rows an iterative HNSW scan visits; zero keeps
+	// pgvector's default
+	MaxScanTuples int
+	// Explain attaches a score explanation to every candidate
+	Explain bool
+	// Profile weights the score features; nil uses the strategy's profile
+	Profile *ScoringProfile
+}
+
+// Merge returns a copy of o with every set field of override applied
+func (o MatchOptions) Merge(override MatchOverrides) MatchOptions {
+	return fromMatchOptions(o.toMatcher().Merge(override.toMatcher()))
+}
+
+// Validate checks that the thresholds are in range and the strategy exists
+func (o MatchOptions) Validate() error {
+	return o.toMatcher().Validate()
+}
+
+func (o MatchOptions) toMatcher() matcher.MatchOptions {
+	return matcher.MatchOptions{
+		Strategy:       o.Strategy,
+		TopN:           o.TopN,
+		MaxDistance:    o.MaxDistance,
+		CandidateLimit: o.CandidateLimit,
+		MinScore:       o.MinScore,
+		MinTfidfScore:  o.MinTfidfScore,
+		EfSearch:       o.EfSearch,
+		Probes:         o.Probes,
+		MaxScanTuples:  o.MaxScanTuples,
+		Explain:        o.Explain,
+		Profile:        (*matcher.ScoringProfile)(o.Profile),
+	}
+}
+
+func fromMatchOptions(o matcher.MatchOptions) MatchOptions {
+	return MatchOptions{
+		Strategy:       o.Strategy,
+		TopN:           o.TopN,
+		MaxDistance:    o.MaxDistance,
+		CandidateLimit: o.CandidateLimit,
+		MinScore:       o.MinScore,
+		MinTfidfScore:  o.MinTfidfScore,
+		EfSearch:       o.EfSearch,
+		Probes:         o.Probes,
+		MaxScanTuples:  o.MaxScanTuples,
+		Explain:        o.Explain,
+		Profile:        (*ScoringProfile)(o.Profile),
+	}
+}
+
+// MatchOverrides are the thresholds a caller sets; nil fields keep the
+// defaults, and set fields are applied as given, zero included
+type MatchOverrides struct {
+	Strategy       string
+	TopN           *int
+	MaxDistance    *float64
+	CandidateLimit *int
+	MinScore       *float64
+	MinTfidfScore  *float64
+	EfSearch       *int
+	Probes         *int
+	MaxScanTuples  *int
+	Explain        *bool
+	Profile        *ScoringProfile
+}
+
+func (o MatchOverrides) toMatcher() matcher.MatchOverrides {
+	return matcher.MatchOverrides{
+		Strategy:       o.Strategy,
+		TopN:           o.TopN,
+		MaxDistance:    o.MaxDistance,
+		CandidateLimit: o.CandidateLimit,
+		MinScore:       o.MinScore,
+		MinTfidfScore:  o.MinTfidfScore,
+		EfSearch:       o.EfSearch,
+		Probes:         o.Probes,
+		MaxScanTuples:  o.MaxScanTuples,
+		Explain:        o.Explain,
+		Profile:        (*matcher.ScoringProfile)(o.Profile),
+	}
+}
+
+// ScoringProfile weights the score features
+type ScoringProfile struct {
+	Similarity  float64
+	Tfidf       float64
+	FirstName   float64
+	LastName    float64
+	Street      float64
+	City        float64
+	PhoneNumber float64
+	ZipCode     float64
+	BinKeyMatch float64
+}
+
+// IndexOptions configures the ANN index over the candidate space
+type IndexOptions struct {
+	// Method is hnsw or ivfflat
+	Method string
+	// HNSW build parameters
+	M              int
+	EfConstruction int
+	// IVFFlat build parameter
+	Lists int
+}
+
+// InputRecord is a record of a run that was matched against the candidate space
+type InputRecord struct {
+	CustomerID   int        `json:"customer_id"`
+	RunID        int        `json:"run_id"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	Street       string     `json:"street"`
+	City         string     `json:"city"`
+	State        string     `json:"state"`
+	ZipCode      string     `json:"zip_code"`
+	PhoneNumber  string     `json:"phone_number"`
+	SourceSystem string     `json:"source_system,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// MatchResult groups the candidates of one input record. Matched is false,
+// and Candidates empty, when no candidate was found for the input.
+type MatchResult struct {
+	Input      InputRecord `json:"input"`
+	Matched    bool        `json:"matched"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+func fromMatchResults(results []matcher.MatchResult) []MatchResult {
+	out := make([]MatchResult, len(results))
+	for i, r := range results {
+		out[i] = MatchResult{
+			Input:      InputRecord(r.Input),
+			Matched:    r.Matched,
+			Candidates: fromCandidates(r.Candidates),
+		}
+	}
+	return out
+}
+
+// Candidate is a scored pair of an input record and a candidate record
+type Candidate struct {
+	InputCustomerID          int     `json:"input_customer_id"`
+	InputRunID               int     `json:"input_run_id"`
+	InputFirstName           string  `json:"input_first_name"`
+	InputLastName            string  `json:"input_last_name"`
+	InputStreet              string  `json:"input_street"`
+	InputCity                string  `json:"input_city"`
+	InputState               string  `json:"input_state"`
+	InputZipCode             string  `json:"input_zip_code"`
+	InputPhoneNumber         string  `json:"input_phone_number"`
+	CandidateCustomerID      int     `json:"candidate_customer_id"`
+	CandidateRunID           int     `json:"candidate_run_id"`
+	CandidateFirstName       string  `json:"candidate_first_name"`
+	CandidateLastName        string  `json:"candidate_last_name"`
+	CandidateStreet          string  `json:"candidate_street"`
+	CandidateCity            string  `json:"candidate_city"`
+	CandidateState           string  `json:"candidate_state"`
+	CandidateZipCode         string  `json:"candidate_zip_code"`
+	CandidatePhoneNumber     string  `json:"candidate_phone_number"`
+	Similarity               float64 `json:"similarity"`
+	BinKeyMatch              bool    `json:"bin_key_match"`
+	TfidfScore               float64 `json:"tfidf_score"`
+	Rank                     int     `json:"rank"`
+	Score                    float64 `json:"score"`
+	TrigramCosineFirstName   float64 `json:"trigram_cosine_first_name"`
+	TrigramCosineLastName    float64 `json:"trigram_cosine_last_name"`
+	TrigramCosineStreet      float64 `json:"trigram_cosine_street"`
+	TrigramCosineCity        float64 `json:"trigram_cosine_city"`
+	TrigramCosinePhoneNumber float64 `json:"trigram_cosine_phone_number"`
+	TrigramCosineZipCode     float64 `json:"trigram_cosine_zip_code"`
+	// Explanation is set when the options ask for one
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+func fromCandidates(candidates []matcher.Candidate) []Candidate {
+	out := make([]Candidate, len(candidates))
+	for i, c := range candidates {
+		out[i] = Candidate{
+			InputCustomerID:          c.InputCustomerID,
+			InputRunID:               c.InputRunID,
+			InputFirstName:           c.InputFirstName,
+			InputLastName:            c.InputLastName,
+			InputStreet:              c.InputStreet,
+			InputCity:                c.InputCity,
+			InputState:               c.InputState,
+			InputZipCode:             c.InputZipCode,
+			InputPhoneNumber:         c.InputPhoneNumber,
+			CandidateCustomerID:      c.CandidateCustomerID,
+			CandidateRunID:           c.CandidateRunID,
+			CandidateFirstName:       c.CandidateFirstName,
+			CandidateLastName:        c.CandidateLastName,
+			CandidateStreet:          c.CandidateStreet,
+			CandidateCity:            c.CandidateCity,
+			CandidateState:           c.CandidateState,
+			CandidateZipCode:         c.CandidateZipCode,
+			CandidatePhoneNumber:     c.CandidatePhoneNumber,
+			Similarity:               c.Similarity,
+			BinKeyMatch:              c.BinKeyMatch,
+			TfidfScore:               c.TfidfScore,
+			Rank:                     c.Rank,
+			Score:                    c.Score,
+			TrigramCosineFirstName:   c.TrigramCosineFirstName,
+			TrigramCosineLastName:    c.TrigramCosineLastName,
+			TrigramCosineStreet:      c.TrigramCosineStreet,
+			TrigramCosineCity:        c.TrigramCosineCity,
+			TrigramCosinePhoneNumber: c.TrigramCosinePhoneNumber,
+			TrigramCosineZipCode:     c.TrigramCosineZipCode,
+			Explanation:              fromExplanation(c.Explanation),
+		}
+	}
+	return out
+}
+
+// Explanation shows how a candidate's score was built
+type Explanation struct {
+	Strategy string `json:"strategy"`
+	// Blocking lists the conditions that put the pair in the candidate set
+	Blocking []string              `json:"blocking"`
+	Features []FeatureContribution `json:"features"`
+	// RawScore is the weighted sum before it is scaled and clamped to [1, 100]
+	RawScore  float64            `json:"raw_score"`
+	Input     StandardizedRecord `json:"input"`
+	Candidate StandardizedRecord `json:"candidate"`
+}
+
+// FeatureContribution is one term of the composite score
+type FeatureContribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// StandardizedRecord holds the standardized forms of a pair's fields, for display
+type StandardizedRecord struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Street      string `json:"street"`
+	Unit        string `json:"unit"`
+	City        string `json:"city"`
+	State       string `json:"state"`
+	ZipCode     string `json:"zip_code"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+func fromExplanation(e *matcher.Explanation) *Explanation {
+	if e == nil {
+		return nil
+	}
+	features := make([]FeatureContribution, len(e.Features))
+	for i, f := range e.Features {
+		features[i] = FeatureContribution(f)
+	}
+	return &Explanation{
+		Strategy:  e.Strategy,
+		Blocking:  e.Blocking,
+		Features:  features,
+		RawScore:  e.RawScore,
+		Input:     StandardizedRecord(e.Input),
+		Candidate: StandardizedRecord(e.Candidate),
+	}
+}
+
+// DuplicateRequest pages through duplicate pairs of the candidate space. Nil
+// thresholds keep the Matcher's defaults.
+type DuplicateRequest struct {
+	MaxDistance    *float64 `json:"max_distance,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+	MinScore       *float64 `json:"min_score,omitempty"`
+	State          string   `json:"state"`
+	ZipCode        string   `json:"zip_code"`
+	PageSize       int      `json:"page_size"`
+	After          int      `json:"after"`
+}
+
+// Options merges the request's thresholds over the given defaults
+func (r DuplicateRequest) Options(defaults MatchOptions) MatchOptions {
+	return fromMatchOptions(matcher.DuplicateRequest(r).Options(defaults.toMatcher()))
+}
+
+// Validate checks the page size and cursor
+func (r DuplicateRequest) Validate() error {
+	return matcher.DuplicateRequest(r).Validate()
+}
+
+// DuplicatePage holds the unique pairs found for one page of records. Pass
+// NextAfter as After to fetch the next page while HasMore is true.
+type DuplicatePage struct {
+	Pairs     []Candidate `json:"pairs"`
+	NextAfter int         `json:"next_after"`
+	HasMore   bool        `json:"has_more"`
+}
+
+// Embedder writes vector embeddings for the records of a run
+type Embedder interface {
+	Embed(ctx context.Context, runID int) error
+}
+
+// PythonEmbedder runs the generate_embeddings.py script
+type PythonEmbedder struct {
+	ScriptPath string
+	// Python is the interpreter; python3 when empty
+	Python string
+	// Env holds NAME=value variables added to the script's environment,
+	// such as the PG* connection settings
+	Env []string
+}
+
+// Embed runs the script for the run
+func (e PythonEmbedder) Embed(ctx context.Context, runID int) error {
+	return wrapError(matcher.PythonEmbedder(e).Embed(ctx, runID))
+}
+
+// StageError reports the pipeline stage a failure happened in
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s stage failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// wrapError carries the stage of an engine failure over to a StageError
+func wrapError(err error) error {
+	var se *matcher.StageError
+	if errors.As(err, &se) {
+		return &StageError{Stage: se.Stage, Err: se.Err}
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scopes granted to API callers. ScopeAdmin implies every other scope.
+const (
+	ScopeMatchRead  = "match:read"
+	ScopeBatchWrite = "batch:write"
+	ScopeAdmin      = "admin"
+)
+
+// APIKeyHeader is the request header that carries a static API key
+const APIKeyHeader = "X-API-Key"
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request does
+	// not carry the kind of credentials it checks
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned for credentials that are present but
+	// unknown, expired or badly signed
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller. Every query it makes is scoped to
+// Tenant; an empty Tenant is the default tenant.
+type Principal struct {
+	Subject string   `json:"subject"`
+	Scopes  []string `json:"scopes"`
+	Tenant  string   `json:"tenant"`
+}
+
+// HasScope reports whether the principal was granted scope, directly or through admin
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator identifies the caller from request headers. It returns
+// ErrNoCredentials when the headers hold none of its credentials so the
+// next authenticator can be tried.
+type Authenticator interface {
+	Authenticate(ctx context.Context, header http.Header) (Principal, error)
+}
+
+// HashAPIKey returns the hex SHA-256 digest under which an API key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore looks up the principal of a hashed API key. It returns
+// ErrInvalidCredentials when the hash is unknown.
+type KeyStore interface {
+	LookupAPIKey(ctx context.Context, hash string) (Principal, error)
+}
+
+// StaticKeys is a KeyStore of API key hashes taken from the configuration
+type StaticKeys map[string]Principal
+
+// LookupAPIKey returns the principal configured for hash
+func (k StaticKeys) LookupAPIKey(ctx context.Context, hash string) (Principal, error) {
+	p, ok := k[hash]
+	if !ok {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return p, nil
+}
+
+// DBKeys is a KeyStore backed by the api_keys table
+type DBKeys struct {
+	Pool *pgxpool.Pool
+}
+
+// LookupAPIKey returns the principal of an unrevoked key in api_keys
+func (k DBKeys) LookupAPIKey(ctx context.Context, hash string) (Principal, error) {
+	var p Principal
+	err := k.Pool.QueryRow(ctx,
+		"SELECT subject, scopes, tenant_id FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL", hash,
+	).Scan(&p.Subject, &p.Scopes, &p.Tenant)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Principal{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("failed to look up API key: %v", err)
+	}
+	return p, nil
+}
+
+// APIKeyAuthenticator accepts static API keys sent in the X-API-Key header.
+// Keys are hashed before lookup, so stores never hold them in clear.
+type APIKeyAuthenticator struct {
+	Keys KeyStore
+}
+
+// Authenticate looks up the hashed API key of the request
+func (a APIKeyAuthenticator) Authenticate(ctx context.Context, header http.Header) (Principal, error) {
+	key := header.Get(APIKeyHeader)
+	if key == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	return a.Keys.LookupAPIKey(ctx, HashAPIKey(key))
+}
+
+// JWTAuthenticator accepts HS256 bearer tokens signed with a shared secret.
+// The subject is taken from "sub", the scopes from the space-separated
+// "scope" claim and the tenant from "tenant". Tokens must carry an expiry.
+type JWTAuthenticator struct {
+	Secret []byte
+	// Issuer and Audience, when set, must match the token's iss and aud claims
+	Issuer   string
+	Audience string
+}
+
+type tokenClaims struct {
+	Scope  string `json:"scope"`
+	Tenant string `json:"tenant"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate validates the bearer token of the request
+func (a JWTAuthenticator) Authenticate(ctx context.Context, header http.Header) (Principal, error) {
+	token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired()}
+	if a.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.Issuer))
+	}
+	if a.Audience != "" {
+		opts = append(opts, jwt.WithAudience(a.Audience))
+	}
+
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.Secret, nil
+	}, opts...)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	return Principal{Subject: claims.Subject, Scopes: strings.Fields(claims.Scope), Tenant: claims.Tenant}, nil
+}
+
+// AuthenticateHeader returns the principal of the first authenticator that
+// accepts the credentials in header. Credentials one authenticator rejects
+// may still be accepted by a later one, such as a key missing from the
+// configuration but present in the api_keys table.
+func AuthenticateHeader(ctx context.Context, header http.Header, auths []Authenticator) (Principal, error) {
+	result := ErrNoCredentials
+	for _, a := range auths {
+		p, err := a.Authenticate(ctx, header)
+		switch {
+		case err == nil:
+			return p, nil
+		case errors.Is(err, ErrNoCredentials):
+		case errors.Is(err, ErrInvalidCredentials):
+			result = err
+		default:
+			return Principal{}, err
+		}
+	}
+	return Principal{}, result
+}
+
+const principalKey = "principal"
+
+// Authenticate rejects requests that no authenticator accepts with 401. The
+// caller's principal is stored on the gin context and its subject recorded
+// on the runs the request creates. With no authenticators every request is
+// let through unauthenticated.
+func Authenticate(auths ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(auths) == 0 {
+			c.Next()
+			return
+		}
+
+		p, err := AuthenticateHeader(c.Request.Context(), c.Request.Header, auths)
+		switch {
+		case errors.Is(err, ErrNoCredentials):
+			c.Header("WWW-Authenticate", `Bearer realm="addressmatchpro"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		case errors.Is(err, ErrInvalidCredentials):
+			c.Header("WWW-Authenticate", `Bearer realm="addressmatchpro", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		case err != nil:
+			respondError(c, "Failed to authenticate", err)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalKey, p)
+		ctx := matcher.WithCaller(c.Request.Context(), p.Subject)
+		c.Request = c.Request.WithContext(matcher.WithTenant(ctx, p.Tenant))
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the authenticated caller of a request, if any
+func PrincipalFrom(c *gin.Context) (Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	p, ok := v.(Principal)
+	return p, ok
+}
+
+// RequireScope rejects authenticated callers without scope with 403.
+// Unauthenticated requests only get this far when auth is disabled.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := PrincipalFrom(c); ok && !p.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("missing scope %s", scope)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireMatchScope needs batch:write for file uploads and match:read otherwise
+func requireMatchScope() gin.HandlerFunc {
+	read, write := RequireScope(ScopeMatchRead), RequireScope(ScopeBatchWrite)
+	return func(c *gin.Context) {
+		if isUpload(c) {
+			write(c)
+		} else {
+			read(c)
+		}
+	}
+}
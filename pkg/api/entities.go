@@ -0,0 +1,116 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING WITHOUT LIMITATION THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ResolveEntitiesRequest overrides the configured clustering thresholds
+type ResolveEntitiesRequest struct {
+	MinScore       *float64 `json:"min_score,omitempty"`
+	MinDensity     *float64 `json:"min_density,omitempty"`
+	MaxDistance    *float64 `json:"max_distance,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+}
+
+// ResolveEntitiesHandler clusters the candidate space into entities under a new run
+func ResolveEntitiesHandler(pool *pgxpool.Pool, matchDefaults matcher.MatchOptions, clusterDefaults matcher.ClusterOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ResolveEntitiesRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		opts := matchDefaults.Merge(matcher.MatchOverrides{MaxDistance: req.MaxDistance, CandidateLimit: req.CandidateLimit})
+		copts := clusterDefaults
+		if req.MinScore != nil {
+			copts.MinScore = *req.MinScore
+		}
+		if req.MinDensity != nil {
+			copts.MinDensity = *req.MinDensity
+		}
+		if err := opts.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := copts.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resolution, err := matcher.ResolveEntities(c.Request.Context(), pool, opts, copts)
+		if err != nil {
+			respondError(c, "Failed to resolve entities", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, resolution)
+	}
+}
+
+// EntityMembersHandler returns the cluster a record belongs to. The optional
+// run_id query parameter selects an entity resolution run, defaulting to the latest.
+func EntityMembersHandler(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		customerID, err := strconv.Atoi(c.Param("customer_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid customer_id: %q", c.Param("customer_id"))})
+			return
+		}
+		runID, err := strconv.Atoi(c.DefaultQuery("run_id", "0"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid run_id: %q", c.Query("run_id"))})
+			return
+		}
+
+		members, err := matcher.LoadEntityMembers(c.Request.Context(), pool, runID, customerID)
+		if errors.Is(err, matcher.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Customer %d has no entity assignment", customerID)})
+			return
+		}
+		if err != nil {
+			respondError(c, "Failed to load entity", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, members)
+	}
+}
@@ -0,0 +1,91 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/TFMV/AddressMatchPro/internal/logging"
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// StatusClientClosedRequest reports a request the client abandoned before it completed
+const StatusClientClosedRequest = 499
+
+// errorStatus maps a failure to the HTTP status it is reported with
+func errorStatus(c *gin.Context, err error) int {
+	switch {
+	case errors.Is(err, matcher.ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, matcher.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	// The driver does not always wrap the context error of an interrupted query
+	if ctxErr := c.Request.Context().Err(); ctxErr != nil {
+		return errorStatus(c, ctxErr)
+	}
+	return http.StatusInternalServerError
+}
+
+// respondError reports a failed request. Client errors are answered with the
+// error text. Server errors are answered with msg, the pipeline stage and the
+// request ID only; the error text may quote record values, so it is logged as
+// error_detail, which the logger redacts unless PII logging is enabled.
+func respondError(c *gin.Context, msg string, err error) {
+	ctx := c.Request.Context()
+	status := errorStatus(c, err)
+	if status < http.StatusInternalServerError {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	body := gin.H{"error": msg, "request_id": logging.RequestIDFromContext(ctx)}
+	attrs := []any{"status", status, "error_detail", err.Error()}
+	var stageErr *matcher.StageError
+	if errors.As(err, &stageErr) {
+		body["stage"] = stageErr.Stage
+		attrs = append(attrs, "stage", stageErr.Stage)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		attrs = append(attrs, "sqlstate", pgErr.Code)
+	}
+	slog.ErrorContext(ctx, msg, attrs...)
+	c.JSON(status, body)
+}
@@ -0,0 +1,96 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING WITHOUT LIMITATION THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BuildGoldenRecordsHandler builds golden records for an entity resolution run
+// or an explicit grouping of customer ids
+func BuildGoldenRecordsHandler(pool *pgxpool.Pool, rules matcher.SurvivorshipRules) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req matcher.GoldenRecordRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		run, err := matcher.BuildGoldenRecords(c.Request.Context(), pool, req, rules)
+		if err != nil {
+			respondError(c, "Failed to build golden records", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, run)
+	}
+}
+
+// GoldenRecordHandler returns a golden record with its field lineage. The
+// optional run_id query parameter selects a golden record run, defaulting to the latest.
+func GoldenRecordHandler(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entityID, err := strconv.Atoi(c.Param("entity_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid entity_id: %q", c.Param("entity_id"))})
+			return
+		}
+		runID, err := strconv.Atoi(c.DefaultQuery("run_id", "0"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid run_id: %q", c.Query("run_id"))})
+			return
+		}
+
+		golden, err := matcher.LoadGoldenRecord(c.Request.Context(), pool, runID, entityID)
+		if errors.Is(err, matcher.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("No golden record for entity %d", entityID)})
+			return
+		}
+		if err != nil {
+			respondError(c, "Failed to load golden record", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, golden)
+	}
+}
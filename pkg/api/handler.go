@@ -31,11 +31,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 
 	"github.com/TFMV/AddressMatchPro/internal/matcher"
 	"github.com/TFMV/AddressMatchPro/pkg/utils"
@@ -43,150 +46,293 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// HealthCheckHandler returns a simple health check response
+// HealthCheckHandler reports liveness: the process is up and serving. It does
+// not touch the database; ReadinessHandler checks that the instance can match.
 func HealthCheckHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	}
 }
 
-// MatchHandler handles both single and batch match requests
-func MatchHandler(pool *pgxpool.Pool) gin.HandlerFunc {
+// MatchHandler handles both single and batch match requests. Batch uploads
+// larger than the limits are rejected with 413.
+func MatchHandler(pool *pgxpool.Pool, defaults matcher.MatchOptions, limits Limits, pipeline Pipeline) gin.HandlerFunc {
+	pipeline = pipeline.withDefaults()
 	return func(c *gin.Context) {
-		log.Println("Entering MatchHandler")
+		// The upload is streamed into the load and cut off while it is read,
+		// so an oversized file is rejected before it is stored
+		if isUpload(c) {
+			if limits.MaxUploadBytes > 0 {
+				c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxUploadBytes)
+			}
+			handleBatchMatch(c, pool, defaults, limits.MaxUploadRows, pipeline)
+			return
+		}
+
 		var req matcher.MatchRequest
-		isBatch := false
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.DebugContext(c.Request.Context(), "single match request", "request", req)
+		handleSingleMatch(c, pool, req, defaults, pipeline)
+	}
+}
 
-		// Check if it's a batch request by looking for a file upload
-		file, err := c.FormFile("file")
-		if err == nil {
-			isBatch = true
+// MatchDuplicates finds unique duplicate pairs within the candidate space (run_id = 0)
+func MatchDuplicates(pool *pgxpool.Pool, defaults matcher.MatchOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// An empty body is the default request
+		var req matcher.DuplicateRequest
+		if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		opts := req.Options(defaults)
+		if err := opts.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		page, err := matcher.FindDuplicates(c.Request.Context(), pool, req, opts)
+		if err != nil {
+			respondError(c, "Failed to find duplicates", err)
+			return
 		}
 
-		if isBatch {
-			handleBatchMatch(c, pool, file)
-		} else {
-			log.Println("Processing single match request")
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// HouseholdsHandler groups the candidate space (run_id = 0) into households by address and unit
+func HouseholdsHandler(pool *pgxpool.Pool, defaults matcher.MatchOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req matcher.HouseholdRequest
+		if c.Request.ContentLength != 0 {
 			if err := c.ShouldBindJSON(&req); err != nil {
-				log.Printf("Error binding JSON: %v", err)
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
-			log.Printf("MatchRequest: %+v", req)
-			handleSingleMatch(c, pool, req)
 		}
-	}
-}
 
-// MatchDuplicates handles duplicate match requests
-func MatchDuplicates(pool *pgxpool.Pool) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req matcher.MatchRequest
-		if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		opts := req.Options(defaults)
+		if err := opts.Validate(); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Match Duplicates supports matching the candidate space (run_id = 0) to itself
-		req.RunID = 0
-
-		// Find matches
-		candidates, err := matcher.FindPotentialMatches(pool, req.RunID, req.TopN)
+		households, err := matcher.FindHouseholds(c.Request.Context(), pool, req, opts)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to find matches: %v", err)})
+			respondError(c, "Failed to find households", err)
 			return
 		}
 
-		c.JSON(http.StatusOK, candidates)
+		c.JSON(http.StatusOK, households)
 	}
 }
 
-func handleSingleMatch(c *gin.Context, pool *pgxpool.Pool, req matcher.MatchRequest) {
-	log.Println("Handling single match")
+func handleSingleMatch(c *gin.Context, pool *pgxpool.Pool, req matcher.MatchRequest, defaults matcher.MatchOptions, pipeline Pipeline) {
+	opts := req.Options(defaults)
+	if err := opts.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Insert the single record into the database with a unique run_id
-	runID := matcher.CreateNewRun(pool, "Single Record Matching")
+	ctx := c.Request.Context()
+	runID, err := matcher.CreateNewRun(ctx, pool, "Single Record Matching")
+	if err != nil {
+		respondError(c, "Failed to create run", err)
+		return
+	}
 	req.RunID = runID
-	req.ScriptPath = "/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/python-ml/generate_embeddings.py" // Set the script path
 
 	// Process the single record
-	if err := matcher.ProcessSingleRecord(pool, req); err != nil {
-		log.Printf("Failed to insert single record: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to insert single record: %v", err)})
+	if err := matcher.ProcessSingleRecord(ctx, pool, req); err != nil {
+		respondError(c, "Failed to insert single record", err)
 		return
 	}
 
-	processAndMatch(pool, runID, req.TopN, 1, c)
+	pipeline.Workers = 1
+	processAndMatch(pool, runID, opts, req.Group, pipeline, c)
 }
 
-func handleBatchMatch(c *gin.Context, pool *pgxpool.Pool, file *multipart.FileHeader) {
-	f, err := file.Open()
+// maxFormFieldBytes caps a form field of a batch upload other than the file
+const maxFormFieldBytes = 1 << 10
+
+// handleBatchMatch reads the form fields that precede the file part, then
+// streams the file part straight into the load. Fields after the file are
+// ignored.
+func handleBatchMatch(c *gin.Context, pool *pgxpool.Pool, defaults matcher.MatchOptions, maxRows int, pipeline Pipeline) {
+	reader, err := c.Request.MultipartReader()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to open file: %v", err)})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer f.Close()
 
-	// Insert the records into the database with a unique run_id
-	runID := matcher.CreateNewRun(pool, "Batch Record Matching")
+	form := url.Values{}
+	var file *multipart.Part
+	for file == nil {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file part"})
+			return
+		}
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		if part.FormName() == "file" {
+			file = part
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, maxFormFieldBytes+1))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		if len(value) > maxFormFieldBytes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("form field %s exceeds %d bytes", part.FormName(), maxFormFieldBytes)})
+			return
+		}
+		form.Add(part.FormName(), string(value))
+	}
 
-	tempFile, err := os.CreateTemp("", "batch-upload-*.csv")
+	opts, err := formMatchOptions(form, defaults)
+	if err == nil {
+		err = opts.Validate()
+	}
+	var group bool
+	if err == nil {
+		group, err = formBool(form, "group")
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create temp file: %v", err)})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer os.Remove(tempFile.Name())
 
-	if _, err := tempFile.ReadFrom(f); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read file: %v", err)})
+	// Stage the upload and insert it into customer_matching as a new run
+	ctx := c.Request.Context()
+	runID, err := utils.LoadBatch(ctx, pool, file, pipeline.LoadTable, maxRows, "Batch Record Matching")
+	if errors.Is(err, utils.ErrTooManyRows) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("upload exceeds %d rows", maxRows)})
 		return
 	}
-
-	// truncate batch_match table
-	if err := matcher.TruncateBatchMatchTable(pool); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to truncate batch_match: %v", err)})
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondUploadError(c, err)
 		return
 	}
-
-	if err := utils.LoadCSV(pool, tempFile.Name()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to load CSV: %v", err)})
+	if err != nil {
+		respondError(c, "Failed to load CSV", err)
 		return
 	}
 
-	// Insert records from load_table into customer_matching with the given run_id
-	err = matcher.InsertFromLoadTable(pool, runID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to insert records into customer_matching: %v", err)})
+	processAndMatch(pool, runID, opts, group, pipeline, c)
+}
+
+// respondUploadError answers a failed read of a batch upload: 413 when it
+// exceeded the byte limit, 400 when it is malformed
+func respondUploadError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("upload exceeds %d bytes", tooLarge.Limit)})
 		return
 	}
-
-	processAndMatch(pool, runID, 10, 10, c)
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 }
 
-func processAndMatch(pool *pgxpool.Pool, runID int, topN int, workers int, c *gin.Context) {
-	log.Println("Processing and matching")
-	// Load reference entities once
-	referenceEntities := matcher.LoadReferenceEntities(pool)
+// formMatchOptions reads matching overrides for a batch from the multipart form fields
+func formMatchOptions(form url.Values, defaults matcher.MatchOptions) (matcher.MatchOptions, error) {
+	var req matcher.MatchRequest
+	var err error
+	req.Strategy = form.Get("strategy")
+	if v := form.Get("top_n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid top_n: %q", v)
+		}
+		req.TopN = &n
+	}
+	if v := form.Get("candidate_limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid candidate_limit: %q", v)
+		}
+		req.CandidateLimit = &n
+	}
+	if v := form.Get("max_distance"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid max_distance: %q", v)
+		}
+		req.MaxDistance = &f
+	}
+	if v := form.Get("min_score"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid min_score: %q", v)
+		}
+		req.MinScore = &f
+	}
+	if req.Explain, err = formBool(form, "explain"); err != nil {
+		return defaults, err
+	}
+	return req.Options(defaults), nil
+}
 
-	// Process customer addresses and generate binary keys with concurrency
-	matcher.ProcessCustomerAddresses(pool, referenceEntities, workers, runID)
+// formBool reads an optional boolean form field; it is false when the field is empty
+func formBool(form url.Values, name string) (bool, error) {
+	v := form.Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q", name, v)
+	}
+	return b, nil
+}
 
-	// Generate TF/IDF vectors
-	matcher.GenerateTFIDF(pool, runID)
+func processAndMatch(pool *pgxpool.Pool, runID int, opts matcher.MatchOptions, group bool, pipeline Pipeline, c *gin.Context) {
+	ctx := c.Request.Context()
+	slog.InfoContext(ctx, "matching run", "run_id", runID, "strategy", opts.Strategy, "workers", pipeline.Workers)
 
-	// Insert vector embeddings using Python script
-	scriptPath := "/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/python-ml/generate_embeddings.py"
-	if err := matcher.GenerateEmbeddingsPythonScript(scriptPath, runID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate embeddings: %v", err)})
+	// Build binary keys, TF/IDF vectors and embeddings for the run
+	if err := matcher.PrepareRun(ctx, pool, runID, pipeline.Workers, pipeline.Embedder); err != nil {
+		respondError(c, "Failed to prepare run", err)
 		return
 	}
 
 	// Find matches
-	candidates, err := matcher.FindPotentialMatches(pool, runID, topN)
+	scored, err := matcher.FindPotentialMatches(ctx, pool, runID, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to find matches: %v", err)})
+		respondError(c, "Failed to find matches", err)
+		return
+	}
+
+	// Persist every scored candidate so the run can be audited and queried later
+	if err := matcher.StoreMatchResults(ctx, pool, runID, opts.Strategy, scored); err != nil {
+		respondError(c, "Failed to store match results", err)
 		return
 	}
+	candidates := matcher.ReturnedCandidates(scored)
 
-	c.JSON(http.StatusOK, candidates)
+	if !group {
+		c.JSON(http.StatusOK, candidates)
+		return
+	}
+
+	inputs, err := matcher.LoadRunInputs(ctx, pool, runID)
+	if err != nil {
+		respondError(c, "Failed to load input records", err)
+		return
+	}
+	c.JSON(http.StatusOK, matcher.GroupByInput(inputs, candidates))
 }
 
@@ -0,0 +1,132 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/pkg/db"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// readinessTimeout bounds all the checks of one readiness probe
+const readinessTimeout = 2 * time.Second
+
+// Check is one readiness check; it fails when the instance cannot match
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a readiness check. The reason a check
+// failed is logged, not returned, since the probe is unauthenticated.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Readiness is the response of the readiness probe
+type Readiness struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// DatabaseChecks verify that the database can serve match queries: it is
+// reachable, has the vector extension, the current schema, the required
+// tables and partitions and the load table, and run 0 holds a candidate space
+func DatabaseChecks(pool *pgxpool.Pool, loadTable string) []Check {
+	if pool == nil {
+		return []Check{{Name: "database", Run: func(context.Context) error {
+			return errors.New("no database connection pool")
+		}}}
+	}
+	if loadTable == "" {
+		loadTable = "batch_match"
+	}
+	return []Check{
+		{Name: "database", Run: pool.Ping},
+		{Name: "vector_extension", Run: func(ctx context.Context) error { return db.CheckVectorExtension(ctx, pool) }},
+		{Name: "schema", Run: func(ctx context.Context) error { return db.CheckSchema(ctx, pool) }},
+		{Name: "tables", Run: func(ctx context.Context) error {
+			return db.CheckTables(ctx, pool, append(db.RequiredTables(), loadTable))
+		}},
+		{Name: "candidate_space", Run: func(ctx context.Context) error { return db.CheckCandidateSpace(ctx, pool) }},
+	}
+}
+
+// ReadinessHandler runs the checks concurrently and reports each one's
+// status and latency. It answers 503 when any check fails or does not
+// finish within the timeout, so that load balancers stop routing to the
+// instance.
+func ReadinessHandler(timeout time.Duration, checks ...Check) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		results := make([]CheckResult, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func(i int, check Check) {
+				defer wg.Done()
+				start := time.Now()
+				err := check.Run(ctx)
+				result := CheckResult{
+					Name:      check.Name,
+					Status:    "ok",
+					LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+				}
+				if err != nil {
+					result.Status = "fail"
+					slog.WarnContext(ctx, "readiness check failed", "check", check.Name, "error", err)
+				}
+				results[i] = result
+			}(i, check)
+		}
+		wg.Wait()
+
+		readiness := Readiness{Status: "ok", Checks: results}
+		status := http.StatusOK
+		for _, r := range results {
+			if r.Status != "ok" {
+				readiness.Status = "unavailable"
+				status = http.StatusServiceUnavailable
+			}
+		}
+		c.JSON(status, readiness)
+	}
+}
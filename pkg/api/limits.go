@@ -0,0 +1,200 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits caps what clients may ask of the API. Zero fields disable a limit.
+type Limits struct {
+	// RequestsPerSecond refills each client's token bucket, which holds up to
+	// Burst requests
+	RequestsPerSecond float64
+	Burst             int
+	// MaxConcurrentBatches caps the batch jobs running at once across all clients
+	MaxConcurrentBatches int
+	// MaxUploadBytes caps the request body of a batch upload
+	MaxUploadBytes int64
+	// MaxUploadRows caps the data rows of a batch upload
+	MaxUploadRows int
+	// Limiter, when set, holds the token buckets instead of one built from
+	// RequestsPerSecond and Burst, so that the gRPC API can share them
+	Limiter *RateLimiter
+}
+
+// rateLimiter returns the configured limiter, or nil when rates are not limited
+func (l Limits) rateLimiter() *RateLimiter {
+	if l.Limiter != nil {
+		return l.Limiter
+	}
+	if l.RequestsPerSecond > 0 {
+		return NewRateLimiter(l.RequestsPerSecond, l.Burst)
+	}
+	return nil
+}
+
+// batchRetryAfter is suggested to batch jobs turned away for lack of a slot
+const batchRetryAfter = 10 * time.Second
+
+// maxBuckets bounds the clients tracked before full buckets are forgotten
+const maxBuckets = 10000
+
+// RateLimiter keeps a token bucket per client
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows each client rate requests per second on average and
+// bursts of up to burst requests
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   math.Max(1, float64(burst)),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the client's bucket. When the bucket is empty it
+// returns false and the time until the next token.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// prune forgets the clients whose buckets have refilled, as they are no
+// different from new clients
+func (l *RateLimiter) prune(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// RateLimit rejects clients that have used up their token bucket with 429.
+// Authenticated clients are told apart by tenant and subject, others by IP
+// address.
+func RateLimit(l *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, wait := l.Allow(clientKey(c)); !ok {
+			tooManyRequests(c, wait, "rate limit exceeded")
+			return
+		}
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if p, ok := PrincipalFrom(c); ok {
+		return ClientKey(p.Tenant, p.Subject, "")
+	}
+	return ClientKey("", "", c.ClientIP())
+}
+
+// ClientKey names the token bucket of a client: its tenant and subject when
+// it is authenticated, its IP address otherwise
+func ClientKey(tenant, subject, ip string) string {
+	if subject != "" {
+		return "principal:" + tenant + "/" + subject
+	}
+	return "ip:" + ip
+}
+
+// limitBatches runs at most cap(slots) batch jobs at once and rejects the
+// rest with 429. Requests for which isBatch is false pass without a slot.
+func limitBatches(slots chan struct{}, isBatch func(*gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if slots == nil || !isBatch(c) {
+			c.Next()
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			c.Next()
+		default:
+			tooManyRequests(c, batchRetryAfter, fmt.Sprintf("too many batch jobs running, at most %d at once", cap(slots)))
+		}
+	}
+}
+
+func isUpload(c *gin.Context) bool {
+	return c.ContentType() == "multipart/form-data"
+}
+
+func always(*gin.Context) bool {
+	return true
+}
+
+// tooManyRequests aborts with 429 and a Retry-After of whole seconds
+func tooManyRequests(c *gin.Context, wait time.Duration, msg string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": msg})
+}
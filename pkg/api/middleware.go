@@ -30,20 +30,78 @@
 package api
 
 import (
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/TFMV/AddressMatchPro/internal/logging"
 	"github.com/gin-gonic/gin"
 )
 
-// RequestLogger logs details about each HTTP request
+// RequestIDHeader carries the request ID to and from clients
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the client-supplied request IDs that are kept
+const maxRequestIDLength = 128
+
+// RequestID tags each request with the client's X-Request-ID, or a new one,
+// and returns it in the response. The ID is carried in the request context,
+// so every log record of the request includes it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger logs details about each HTTP request. Server errors are
+// logged at error level and client errors at warn level.
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
 		c.Next()
-		duration := time.Since(startTime)
-		log.Printf("Request: %s %s - %d (%s)", c.Request.Method, c.Request.URL, c.Writer.Status(), duration)
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(startTime)),
+			slog.String("client_ip", c.ClientIP()),
+		)
 	}
 }
 
@@ -60,15 +118,15 @@ func ErrorHandler() gin.HandlerFunc {
 // RequestValidator validates incoming JSON requests
 func RequestValidator() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		log.Println("Validating request")
+		ctx := c.Request.Context()
 		if c.Request.Method == http.MethodPost {
 			var requestBody map[string]interface{}
 			if err := c.ShouldBindJSON(&requestBody); err != nil {
-				log.Printf("Error binding JSON: %v", err)
+				slog.WarnContext(ctx, "invalid request body", "error", err)
 				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
 				return
 			}
-			log.Printf("Request body: %v", requestBody)
+			slog.DebugContext(ctx, "request body", "body", logging.Map(requestBody))
 		}
 		c.Next()
 	}
@@ -30,14 +30,78 @@
 package api
 
 import (
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Options configures the API handlers
+type Options struct {
+	// MatchDefaults are used for any matching parameter a request leaves unset
+	MatchDefaults matcher.MatchOptions
+	// ClusterDefaults are the entity resolution thresholds
+	ClusterDefaults matcher.ClusterOptions
+	// Survivorship picks golden record field values
+	Survivorship matcher.SurvivorshipRules
+	// Auth identifies callers; when empty the API is served unauthenticated
+	Auth []Authenticator
+	// Limits caps request rates, concurrent batch jobs and upload sizes
+	Limits Limits
+	// Pipeline prepares the records of a match run
+	Pipeline Pipeline
+}
+
+// Pipeline configures how the records of a match run are loaded and prepared
+type Pipeline struct {
+	// LoadTable is the table each batch upload's temporary staging table is
+	// shaped like; batch_match when empty
+	LoadTable string
+	// Workers is the number of binary key workers of a batch run; 10 when zero
+	Workers int
+	// Embedder writes the vector embeddings of a run; the Python script
+	// in python-ml when nil
+	Embedder matcher.Embedder
+}
+
+// withDefaults fills in the unset pipeline settings
+func (p Pipeline) withDefaults() Pipeline {
+	if p.LoadTable == "" {
+		p.LoadTable = "batch_match"
+	}
+	if p.Workers <= 0 {
+		p.Workers = 10
+	}
+	if p.Embedder == nil {
+		p.Embedder = matcher.PythonEmbedder{ScriptPath: "python-ml/generate_embeddings.py"}
+	}
+	return p
+}
+
 // SetupRoutes sets up the HTTP routes for the API
-func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool) {
+func SetupRoutes(router *gin.Engine, pool *pgxpool.Pool, opts Options) {
+	router.Use(RequestID(), Metrics(), Tracing(), RequestLogger())
+	router.GET("/metrics", MetricsHandler())
+	router.GET("/livez", HealthCheckHandler())
+	router.GET("/readyz", ReadinessHandler(readinessTimeout, DatabaseChecks(pool, opts.Pipeline.LoadTable)...))
 	router.GET("/api/v1/healthz", HealthCheckHandler())
-	router.POST("/api/v1/match", MatchHandler(pool))
-	router.POST("/api/v1/duplicates", MatchHandler(pool))
+
+	v1 := router.Group("/api/v1", Authenticate(opts.Auth...))
+	if limiter := opts.Limits.rateLimiter(); limiter != nil {
+		v1.Use(RateLimit(limiter))
+	}
+	var batchSlots chan struct{}
+	if opts.Limits.MaxConcurrentBatches > 0 {
+		batchSlots = make(chan struct{}, opts.Limits.MaxConcurrentBatches)
+	}
+
+	read, write := RequireScope(ScopeMatchRead), RequireScope(ScopeBatchWrite)
+	v1.POST("/match", requireMatchScope(), limitBatches(batchSlots, isUpload), MatchHandler(pool, opts.MatchDefaults, opts.Limits, opts.Pipeline))
+	v1.POST("/duplicates", read, MatchDuplicates(pool, opts.MatchDefaults))
+	v1.POST("/households", read, HouseholdsHandler(pool, opts.MatchDefaults))
+	v1.POST("/entities/resolve", write, limitBatches(batchSlots, always), ResolveEntitiesHandler(pool, opts.MatchDefaults, opts.ClusterDefaults))
+	v1.GET("/entities/:customer_id", read, EntityMembersHandler(pool))
+	v1.GET("/runs/:id/matches", read, RunMatchesHandler(pool))
+	v1.POST("/golden-records", write, limitBatches(batchSlots, always), BuildGoldenRecordsHandler(pool, opts.Survivorship))
+	v1.GET("/golden-records/:entity_id", read, GoldenRecordHandler(pool))
 }
 
@@ -0,0 +1,85 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING WITHOUT LIMITATION THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunMatchesHandler returns the stored match results of a run, optionally
+// filtered by the min_score, input_id and returned query parameters
+func RunMatchesHandler(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid run id: %q", c.Param("id"))})
+			return
+		}
+
+		var filter matcher.MatchResultFilter
+		if v := c.Query("min_score"); v != "" {
+			if filter.MinScore, err = strconv.ParseFloat(v, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid min_score: %q", v)})
+				return
+			}
+		}
+		if v := c.Query("input_id"); v != "" {
+			if filter.InputCustomerID, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input_id: %q", v)})
+				return
+			}
+		}
+		if v := c.Query("returned"); v != "" {
+			if filter.Returned, err = strconv.ParseBool(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid returned: %q", v)})
+				return
+			}
+		}
+
+		results, err := matcher.LoadMatchResults(c.Request.Context(), pool, runID, filter)
+		if errors.Is(err, matcher.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Run %d not found", runID)})
+			return
+		}
+		if err != nil {
+			respondError(c, "Failed to load match results", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
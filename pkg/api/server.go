@@ -0,0 +1,120 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ServerOptions configures the HTTP listener of the API. A zero timeout
+// means no timeout.
+type ServerOptions struct {
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// TLSCertFile and TLSKeyFile serve HTTPS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Server serves the API over HTTP or HTTPS. Requests run in a context that
+// is canceled when a graceful shutdown runs out of time, so that in-flight
+// pipelines stop and release their connections before the pool is closed.
+type Server struct {
+	srv    *http.Server
+	opts   ServerOptions
+	cancel context.CancelFunc
+}
+
+// NewServer returns a server for the handler
+func NewServer(handler http.Handler, opts ServerOptions) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		srv: &http.Server{
+			Addr:              opts.Addr,
+			Handler:           handler,
+			ReadHeaderTimeout: opts.ReadHeaderTimeout,
+			ReadTimeout:       opts.ReadTimeout,
+			WriteTimeout:      opts.WriteTimeout,
+			IdleTimeout:       opts.IdleTimeout,
+			BaseContext:       func(net.Listener) context.Context { return ctx },
+		},
+		opts:   opts,
+		cancel: cancel,
+	}
+}
+
+// TLS reports whether the server serves HTTPS
+func (s *Server) TLS() bool {
+	return s.opts.TLSCertFile != "" && s.opts.TLSKeyFile != ""
+}
+
+// ListenAndServe listens on the configured address and serves until Shutdown
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.opts.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves the connections of l until Shutdown. It returns nil once the
+// server is shut down.
+func (s *Server) Serve(l net.Listener) error {
+	var err error
+	if s.TLS() {
+		err = s.srv.ServeTLS(l, s.opts.TLSCertFile, s.opts.TLSKeyFile)
+	} else {
+		err = s.srv.Serve(l)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests,
+// batch matches included, to finish. When ctx is done first, the contexts
+// of the remaining requests are canceled, their connections are closed and
+// ctx's error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.srv.Shutdown(ctx)
+	s.cancel()
+	if err != nil {
+		s.srv.Close()
+	}
+	return err
+}
@@ -0,0 +1,355 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+// Package client is a Go SDK for the AddressMatchPro HTTP API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is a non-2xx response from the API
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Stage is the pipeline stage that failed, when the server reports one
+	Stage string
+	// RetryAfter is the delay the server asked for in a Retry-After header
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Stage != "" {
+		return fmt.Sprintf("addressmatchpro: %d %s (stage %s)", e.StatusCode, e.Message, e.Stage)
+	}
+	return fmt.Sprintf("addressmatchpro: %d %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether the server turned the request away before acting
+// on it, so that it may be sent again even if it is not idempotent: a 429, or
+// a 503 with a Retry-After
+func (e *APIError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return e.RetryAfter > 0
+	}
+	return false
+}
+
+// retryable reports whether a request failing with e may be retried. Gateway
+// failures may come after the server acted on the request, so only idempotent
+// requests are retried on them.
+func (e *APIError) retryable(idempotent bool) bool {
+	if e.Temporary() {
+		return true
+	}
+	switch e.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// Options configures a Client
+type Options struct {
+	// BaseURL is the server address, e.g. http://localhost:8080
+	BaseURL string
+	// HTTPClient is used for requests; defaults to a client with Timeout
+	HTTPClient *http.Client
+	// Timeout bounds each attempt when HTTPClient is not set (default 5 minutes,
+	// as batch matching runs the whole pipeline)
+	Timeout time.Duration
+	// APIKey is sent in the X-API-Key header
+	APIKey string
+	// BearerToken is sent in the Authorization header
+	BearerToken string
+	// MaxRetries is the number of retries of a failed request (default 3; -1
+	// disables). Matches create runs, so they are retried only when the server
+	// turned them away with a 429, or a 503 with a Retry-After. Reads are also
+	// retried on transport failures and on 502, 503 and 504.
+	MaxRetries int
+	// Backoff is the first retry delay, doubled on every retry (default 500ms)
+	Backoff time.Duration
+	// MaxBackoff caps the retry delay, including one asked for by Retry-After (default 10s)
+	MaxBackoff time.Duration
+}
+
+// Client calls the AddressMatchPro API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	http       *http.Client
+	apiKey     string
+	token      string
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// New returns a Client for the server at opts.BaseURL
+func New(opts Options) (*Client, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("client: a base URL is required")
+	}
+	c := &Client{
+		baseURL:    strings.TrimRight(opts.BaseURL, "/"),
+		http:       opts.HTTPClient,
+		apiKey:     opts.APIKey,
+		token:      opts.BearerToken,
+		maxRetries: opts.MaxRetries,
+		backoff:    opts.Backoff,
+		maxBackoff: opts.MaxBackoff,
+	}
+	if c.http == nil {
+		timeout := opts.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Minute
+		}
+		c.http = &http.Client{Timeout: timeout}
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = 3
+	}
+	if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.backoff <= 0 {
+		c.backoff = 500 * time.Millisecond
+	}
+	if c.maxBackoff <= 0 {
+		c.maxBackoff = 10 * time.Second
+	}
+	return c, nil
+}
+
+// Health checks that the server is up
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/api/v1/healthz", "", nil, true, nil)
+}
+
+// Match matches a single record and returns its candidates
+func (c *Client) Match(ctx context.Context, req MatchRequest) ([]Candidate, error) {
+	req.Group = false
+	var candidates []Candidate
+	err := c.postJSON(ctx, "/api/v1/match", req, false, &candidates)
+	return candidates, err
+}
+
+// MatchGrouped matches a single record and returns it with its candidates
+func (c *Client) MatchGrouped(ctx context.Context, req MatchRequest) ([]MatchResult, error) {
+	req.Group = true
+	var results []MatchResult
+	err := c.postJSON(ctx, "/api/v1/match", req, false, &results)
+	return results, err
+}
+
+// Ptr returns a pointer to v, for setting optional request fields
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// BatchOptions are the matching overrides of a batch upload; nil fields keep
+// the server's defaults
+type BatchOptions struct {
+	Strategy       string
+	TopN           *int
+	CandidateLimit *int
+	MaxDistance    *float64
+	MinScore       *float64
+	Explain        bool
+}
+
+// MatchBatch uploads a CSV of records and returns the candidates of all of them
+func (c *Client) MatchBatch(ctx context.Context, csv io.Reader, opts BatchOptions) ([]Candidate, error) {
+	var candidates []Candidate
+	err := c.postBatch(ctx, csv, opts, false, &candidates)
+	return candidates, err
+}
+
+// MatchBatchGrouped uploads a CSV of records and returns one result per record
+func (c *Client) MatchBatchGrouped(ctx context.Context, csv io.Reader, opts BatchOptions) ([]MatchResult, error) {
+	var results []MatchResult
+	err := c.postBatch(ctx, csv, opts, true, &results)
+	return results, err
+}
+
+// Duplicates returns one page of duplicate pairs within the candidate space
+func (c *Client) Duplicates(ctx context.Context, req DuplicateRequest) (DuplicatePage, error) {
+	var page DuplicatePage
+	err := c.postJSON(ctx, "/api/v1/duplicates", req, true, &page)
+	return page, err
+}
+
+// postJSON posts in as JSON; idempotent marks a request that has no effect
+// beyond its response, which may be retried after any failure
+func (c *Client) postJSON(ctx context.Context, path string, in interface{}, idempotent bool, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, path, "application/json", body, idempotent, out)
+}
+
+func (c *Client) postBatch(ctx context.Context, csv io.Reader, opts BatchOptions, group bool, out interface{}) error {
+	// The form is buffered so that it can be sent again on a retry
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	fields := map[string]string{"group": strconv.FormatBool(group), "explain": strconv.FormatBool(opts.Explain)}
+	if opts.Strategy != "" {
+		fields["strategy"] = opts.Strategy
+	}
+	if opts.TopN != nil {
+		fields["top_n"] = strconv.Itoa(*opts.TopN)
+	}
+	if opts.CandidateLimit != nil {
+		fields["candidate_limit"] = strconv.Itoa(*opts.CandidateLimit)
+	}
+	if opts.MaxDistance != nil {
+		fields["max_distance"] = strconv.FormatFloat(*opts.MaxDistance, 'f', -1, 64)
+	}
+	if opts.MinScore != nil {
+		fields["min_score"] = strconv.FormatFloat(*opts.MinScore, 'f', -1, 64)
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("file", "batch.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, csv); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/api/v1/match", form.FormDataContentType(), buf.Bytes(), false, out)
+}
+
+// do sends the request, retrying the failures the request's idempotency
+// allows with exponential backoff, and decodes a 2xx JSON response into out
+func (c *Client) do(ctx context.Context, method, path, contentType string, body []byte, idempotent bool, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		retry, retryAfter, err := c.attempt(ctx, method, path, contentType, body, idempotent, out)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= c.maxRetries {
+			return err
+		}
+
+		delay := c.backoff << attempt
+		if delay > c.maxBackoff || delay <= 0 {
+			delay = c.maxBackoff
+		}
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		if retryAfter > 0 {
+			delay = min(retryAfter, c.maxBackoff)
+		}
+		// Waiting past the caller's deadline would only delay the error
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends one request. It reports whether a failure may be retried and
+// the server's Retry-After delay, if any.
+func (c *Client) attempt(ctx context.Context, method, path, contentType string, body []byte, idempotent bool, out interface{}) (bool, time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, 0, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The request may have reached the server, so transport failures are
+		// retried for idempotent requests only, unless the caller gave up
+		return idempotent && ctx.Err() == nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var payload struct {
+			Error string `json:"error"`
+			Stage string `json:"stage"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if json.Unmarshal(data, &payload) != nil || payload.Error == "" {
+			payload.Error = strings.TrimSpace(string(data))
+		}
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: payload.Error, Stage: payload.Stage, RetryAfter: retryAfter}
+		return apiErr.retryable(idempotent), retryAfter, apiErr
+	}
+
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return false, 0, err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, 0, fmt.Errorf("client: failed to decode %s response: %v", path, err)
+	}
+	return false, 0, nil
+}
@@ -0,0 +1,158 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package client
+
+import "time"
+
+// MatchRequest is the body of POST /api/v1/match for a single record. Nil
+// thresholds keep the server's defaults; set ones are applied as given.
+type MatchRequest struct {
+	FirstName      string   `json:"first_name"`
+	LastName       string   `json:"last_name"`
+	PhoneNumber    string   `json:"phone_number"`
+	Street         string   `json:"street"`
+	City           string   `json:"city"`
+	State          string   `json:"state"`
+	ZipCode        string   `json:"zip_code"`
+	Strategy       string   `json:"strategy,omitempty"`
+	TopN           *int     `json:"top_n,omitempty"`
+	MaxDistance    *float64 `json:"max_distance,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+	MinScore       *float64 `json:"min_score,omitempty"`
+	// Group returns one {input, candidates} entry per input record
+	Group bool `json:"group"`
+	// Explain attaches a score explanation to every candidate
+	Explain bool `json:"explain"`
+}
+
+// InputRecord is a record of a run that was matched against the candidate space
+type InputRecord struct {
+	CustomerID   int        `json:"customer_id"`
+	RunID        int        `json:"run_id"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	Street       string     `json:"street"`
+	City         string     `json:"city"`
+	State        string     `json:"state"`
+	ZipCode      string     `json:"zip_code"`
+	PhoneNumber  string     `json:"phone_number"`
+	SourceSystem string     `json:"source_system,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// MatchResult groups the candidates of one input record
+type MatchResult struct {
+	Input      InputRecord `json:"input"`
+	Matched    bool        `json:"matched"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Candidate is a scored pair of an input record and a candidate record
+type Candidate struct {
+	InputCustomerID          int          `json:"input_customer_id"`
+	InputRunID               int          `json:"input_run_id"`
+	InputFirstName           string       `json:"input_first_name"`
+	InputLastName            string       `json:"input_last_name"`
+	InputStreet              string       `json:"input_street"`
+	InputCity                string       `json:"input_city"`
+	InputState               string       `json:"input_state"`
+	InputZipCode             string       `json:"input_zip_code"`
+	InputPhoneNumber         string       `json:"input_phone_number"`
+	CandidateCustomerID      int          `json:"candidate_customer_id"`
+	CandidateRunID           int          `json:"candidate_run_id"`
+	CandidateFirstName       string       `json:"candidate_first_name"`
+	CandidateLastName        string       `json:"candidate_last_name"`
+	CandidateStreet          string       `json:"candidate_street"`
+	CandidateCity            string       `json:"candidate_city"`
+	CandidateState           string       `json:"candidate_state"`
+	CandidateZipCode         string       `json:"candidate_zip_code"`
+	CandidatePhoneNumber     string       `json:"candidate_phone_number"`
+	Similarity               float64      `json:"similarity"`
+	BinKeyMatch              bool         `json:"bin_key_match"`
+	TfidfScore               float64      `json:"tfidf_score"`
+	Rank                     int          `json:"rank"`
+	Score                    float64      `json:"score"`
+	TrigramCosineFirstName   float64      `json:"trigram_cosine_first_name"`
+	TrigramCosineLastName    float64      `json:"trigram_cosine_last_name"`
+	TrigramCosineStreet      float64      `json:"trigram_cosine_street"`
+	TrigramCosineCity        float64      `json:"trigram_cosine_city"`
+	TrigramCosinePhoneNumber float64      `json:"trigram_cosine_phone_number"`
+	TrigramCosineZipCode     float64      `json:"trigram_cosine_zip_code"`
+	Explanation              *Explanation `json:"explanation,omitempty"`
+}
+
+// Explanation shows how a candidate's score was built
+type Explanation struct {
+	Strategy  string                `json:"strategy"`
+	Blocking  []string              `json:"blocking"`
+	Features  []FeatureContribution `json:"features"`
+	RawScore  float64               `json:"raw_score"`
+	Input     StandardizedRecord    `json:"input"`
+	Candidate StandardizedRecord    `json:"candidate"`
+}
+
+// FeatureContribution is one term of the composite score
+type FeatureContribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// StandardizedRecord holds the standardized forms of a pair's fields, for display
+type StandardizedRecord struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Street      string `json:"street"`
+	Unit        string `json:"unit"`
+	City        string `json:"city"`
+	State       string `json:"state"`
+	ZipCode     string `json:"zip_code"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// DuplicateRequest is the body of POST /api/v1/duplicates
+type DuplicateRequest struct {
+	MaxDistance    *float64 `json:"max_distance,omitempty"`
+	CandidateLimit *int     `json:"candidate_limit,omitempty"`
+	MinScore       *float64 `json:"min_score,omitempty"`
+	State          string   `json:"state,omitempty"`
+	ZipCode        string   `json:"zip_code,omitempty"`
+	PageSize       int      `json:"page_size,omitempty"`
+	After          int      `json:"after,omitempty"`
+}
+
+// DuplicatePage is one page of duplicate pairs. Pass NextAfter as After to
+// fetch the next page while HasMore is true.
+type DuplicatePage struct {
+	Pairs     []Candidate `json:"pairs"`
+	NextAfter int         `json:"next_after"`
+	HasMore   bool        `json:"has_more"`
+}
@@ -31,34 +31,292 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
+	"github.com/TFMV/AddressMatchPro/internal/logging"
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
 	"gopkg.in/yaml.v2"
 )
 
+// Config is the configuration of the server and the command line tools. It
+// is read from a YAML file, then overridden by AMP_* environment variables
+// and -set flags.
 type Config struct {
-	DBCreds struct {
-		Host     string `yaml:"host"`
-		Port     string `yaml:"port"`
-		Username string `yaml:"username"`
-		Password string `yaml:"password"`
-		Database string `yaml:"database"`
-	} `yaml:"db_creds"`
-}
-
-// LoadConfig loads the configuration from a YAML file
-func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read config file: %v", err)
+	DBCreds      DBConfig           `yaml:"db_creds"`
+	Server       ServerConfig       `yaml:"server"`
+	Pipeline     PipelineConfig     `yaml:"pipeline"`
+	Embedding    EmbeddingConfig    `yaml:"embedding"`
+	Matching     MatchingConfig     `yaml:"matching"`
+	Scoring      ScoringConfig      `yaml:"scoring"`
+	VectorIndex  VectorIndexConfig  `yaml:"vector_index"`
+	Clustering   ClusteringConfig   `yaml:"clustering"`
+	Survivorship SurvivorshipConfig `yaml:"survivorship"`
+	GRPC         GRPCConfig         `yaml:"grpc"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Limits       LimitsConfig       `yaml:"limits"`
+	Tracing      TracingConfig      `yaml:"tracing"`
+	Logging      LoggingConfig      `yaml:"logging"`
+}
+
+// DBConfig holds the database connection settings
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Database string `yaml:"database"`
+	// LoadTable is the table each batch upload's temporary staging table is
+	// shaped like
+	LoadTable string `yaml:"load_table"`
+}
+
+// PGEnv returns the connection settings as the libpq PG* environment
+// variables, for the Python scripts connecting to the same database
+func (d DBConfig) PGEnv() []string {
+	return []string{
+		"PGHOST=" + d.Host,
+		"PGPORT=" + d.Port,
+		"PGUSER=" + d.Username,
+		"PGPASSWORD=" + d.Password,
+		"PGDATABASE=" + d.Database,
 	}
+}
 
-	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal config file: %v", err)
+// ServerConfig holds the HTTP listener settings. Durations are written like
+// 30s or 5m; zero disables a timeout.
+type ServerConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout bounds the drain of in-flight requests on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// TLSCertFile and TLSKeyFile serve HTTPS, and gRPC over TLS, when both are set
+	TLSCertFile string `yaml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file"`
+}
+
+// PipelineConfig holds the settings of the steps preparing a run for matching
+type PipelineConfig struct {
+	// Workers is the number of binary key workers per run
+	Workers int `yaml:"workers"`
+}
+
+// EmbeddingConfig locates the script writing the vector embeddings
+type EmbeddingConfig struct {
+	Python     string `yaml:"python"`
+	ScriptPath string `yaml:"script_path"`
+}
+
+// MatchingConfig holds the default matching parameters; requests may override them
+type MatchingConfig struct {
+	Strategy       string  `yaml:"strategy"`
+	TopN           int     `yaml:"top_n"`
+	MaxDistance    float64 `yaml:"max_distance"`
+	CandidateLimit int     `yaml:"candidate_limit"`
+	MinScore       float64 `yaml:"min_score"`
+	MinTfidfScore  float64 `yaml:"min_tfidf_score"`
+}
+
+// ScoringConfig weights the features of the composite score. When every
+// weight is zero each strategy uses its own profile.
+type ScoringConfig struct {
+	Similarity  float64 `yaml:"similarity"`
+	Tfidf       float64 `yaml:"tfidf"`
+	FirstName   float64 `yaml:"first_name"`
+	LastName    float64 `yaml:"last_name"`
+	Street      float64 `yaml:"street"`
+	City        float64 `yaml:"city"`
+	PhoneNumber float64 `yaml:"phone_number"`
+	ZipCode     float64 `yaml:"zip_code"`
+	BinKeyMatch float64 `yaml:"bin_key_match"`
+}
+
+// IsSet reports whether any weight is configured
+func (s ScoringConfig) IsSet() bool {
+	return s != ScoringConfig{}
+}
+
+// VectorIndexConfig holds the pgvector ANN index build and search parameters
+type VectorIndexConfig struct {
+	Method         string `yaml:"method"`
+	M              int    `yaml:"m"`
+	EfConstruction int    `yaml:"ef_construction"`
+	Lists          int    `yaml:"lists"`
+	EfSearch       int    `yaml:"ef_search"`
+	Probes         int    `yaml:"probes"`
+	// MaxScanTuples caps the rows an iterative HNSW scan visits per query
+	MaxScanTuples int `yaml:"max_scan_tuples"`
+}
+
+// ClusteringConfig holds the entity resolution thresholds
+type ClusteringConfig struct {
+	MinScore   float64 `yaml:"min_score"`
+	MinDensity float64 `yaml:"min_density"`
+}
+
+// SurvivorshipConfig holds the golden record survivorship rules
+type SurvivorshipConfig struct {
+	DefaultRule    string            `yaml:"default_rule"`
+	Fields         map[string]string `yaml:"fields"`
+	SourcePriority []string          `yaml:"source_priority"`
+}
+
+// GRPCConfig holds the gRPC API listener settings
+type GRPCConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+// AuthConfig holds the API authentication settings
+type AuthConfig struct {
+	// Disabled serves the API without authentication
+	Disabled bool `yaml:"disabled"`
+	// APIKeys are static keys, stored as hex SHA-256 hashes
+	APIKeys []APIKeyConfig `yaml:"api_keys"`
+	// APIKeyTable also accepts the keys stored in the api_keys table
+	APIKeyTable bool      `yaml:"api_key_table"`
+	JWT         JWTConfig `yaml:"jwt"`
+}
+
+// APIKeyConfig is one static API key
+type APIKeyConfig struct {
+	Subject string   `yaml:"subject"`
+	KeyHash string   `yaml:"key_hash"`
+	Scopes  []string `yaml:"scopes"`
+	// Tenant the key's caller belongs to, default when empty
+	Tenant string `yaml:"tenant"`
+}
+
+// JWTConfig enables HS256 bearer tokens when Secret is set
+type JWTConfig struct {
+	Secret   string `yaml:"secret"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+}
+
+// LimitsConfig holds the request quotas and upload limits; zero disables a limit
+type LimitsConfig struct {
+	// RequestsPerSecond and Burst size one token bucket per client, shared by
+	// the REST and gRPC APIs
+	RequestsPerSecond    float64 `yaml:"requests_per_second"`
+	Burst                int     `yaml:"burst"`
+	MaxConcurrentBatches int     `yaml:"max_concurrent_batches"`
+	MaxUploadBytes       int64   `yaml:"max_upload_bytes"`
+	MaxUploadRows        int     `yaml:"max_upload_rows"`
+}
+
+// TracingConfig selects the OpenTelemetry span exporter
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// LoggingConfig holds the log level and format
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	// LogPII logs names, phones and streets unmasked; for local debugging only
+	LogPII bool `yaml:"log_pii"`
+}
+
+// Default returns the configuration used for any setting the file and the
+// overrides leave unset
+func Default() *Config {
+	match := matcher.DefaultMatchOptions()
+	return &Config{
+		DBCreds: DBConfig{
+			Host:      "localhost",
+			Port:      "5432",
+			Username:  "postgres",
+			Database:  "tfmv",
+			LoadTable: "batch_match",
+		},
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       5 * time.Minute,
+			WriteTimeout:      15 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   8 * time.Second,
+		},
+		Pipeline: PipelineConfig{Workers: 10},
+		Matching: MatchingConfig{
+			Strategy:       match.Strategy,
+			TopN:           match.TopN,
+			MaxDistance:    match.MaxDistance,
+			CandidateLimit: match.CandidateLimit,
+			MinScore:       match.MinScore,
+			MinTfidfScore:  match.MinTfidfScore,
+		},
+		Clustering: ClusteringConfig{MinScore: matcher.DefaultClusterOptions().MinScore},
+		Embedding:  EmbeddingConfig{Python: "python3", ScriptPath: "python-ml/generate_embeddings.py"},
+		Tracing:    TracingConfig{Exporter: "none", SampleRatio: 1},
+		Logging:    LoggingConfig{Level: "info", Format: "json"},
 	}
+}
 
-	return &config, nil
+// LoadConfig loads the configuration from a YAML file over the defaults,
+// applies the AMP_* environment variables and then the key=value overrides,
+// and validates the result. An empty path loads no file. Unknown keys in the
+// file are errors.
+func LoadConfig(configPath string, overrides ...string) (*Config, error) {
+	config := Default()
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read config file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal config file %s: %v", configPath, err)
+		}
+	}
+
+	if err := config.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if err := config.SetOverride(override); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Redacted returns a copy of the configuration with its secrets masked
+func (c Config) Redacted() Config {
+	mask := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return logging.Redacted
+	}
+	c.DBCreds.Password = mask(c.DBCreds.Password)
+	c.Auth.JWT.Secret = mask(c.Auth.JWT.Secret)
+	keys := make([]APIKeyConfig, len(c.Auth.APIKeys))
+	for i, k := range c.Auth.APIKeys {
+		k.KeyHash = mask(k.KeyHash)
+		keys[i] = k
+	}
+	c.Auth.APIKeys = keys
+	return c
+}
+
+// Print writes the configuration as YAML with its secrets masked
+func (c Config) Print(w io.Writer) error {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
 }
 
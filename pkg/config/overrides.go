@@ -0,0 +1,265 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the environment variables overriding settings. A key is
+// upper-cased with its dots turned into underscores: matching.top_n is
+// overridden by AMP_MATCHING_TOP_N.
+const EnvPrefix = "AMP_"
+
+// EnvName returns the environment variable overriding a key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Keys returns the keys of every setting that can be overridden, such as
+// db_creds.host, in schema order
+func Keys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := prefix + yamlName(field)
+			switch {
+			case field.Type.Kind() == reflect.Struct:
+				walk(field.Type, key+".")
+			case settable(field.Type):
+				keys = append(keys, key)
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return keys
+}
+
+// Set overrides the setting of a key, such as matching.top_n, with a value
+// parsed for its type. Lists are comma separated and maps are comma
+// separated name=value pairs.
+func (c *Config) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		field, ok := fieldByYAMLName(v, name)
+		if !ok {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		v = field
+	}
+	if !settable(v.Type()) {
+		return fmt.Errorf("setting %q cannot be overridden", key)
+	}
+	if err := setValue(v, value); err != nil {
+		return fmt.Errorf("invalid value %q for %s: %v", value, key, err)
+	}
+	return nil
+}
+
+// SetOverride applies a key=value override
+func (c *Config) SetOverride(override string) error {
+	key, value, ok := strings.Cut(override, "=")
+	if !ok {
+		return fmt.Errorf("override %q is not key=value", override)
+	}
+	return c.Set(strings.TrimSpace(key), value)
+}
+
+// applyEnv applies the environment variable of every key that is set
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		if value, ok := lookup(EnvName(key)); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %v", EnvName(key), err)
+			}
+		}
+	}
+	return nil
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+func fieldByYAMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if yamlName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// settable reports whether a setting of the type can be parsed from a string
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func setValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return errors.New("not a duration")
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		fallthrough
+	case reflect.Int:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		m := map[string]string{}
+		for _, pair := range strings.Split(value, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			name, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q is not name=value", pair)
+			}
+			m[strings.TrimSpace(name)] = strings.TrimSpace(val)
+		}
+		v.Set(reflect.ValueOf(m))
+	}
+	return nil
+}
+
+// Overrides collects repeated -set key=value flags
+type Overrides []string
+
+func (o *Overrides) String() string {
+	return strings.Join(*o, ",")
+}
+
+// Set appends an override
+func (o *Overrides) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not key=value", value)
+	}
+	*o = append(*o, value)
+	return nil
+}
+
+// Flags are the configuration flags shared by the commands
+type Flags struct {
+	// Path is the configuration file
+	Path string
+	// Overrides are applied over the file and the environment
+	Overrides Overrides
+	// Print asks the command to print the effective configuration and exit
+	Print bool
+	// explicit is set when the file was named by a flag or CONFIG_PATH
+	explicit bool
+}
+
+// defaultPath is read when no configuration file is named
+const defaultPath = "config.yaml"
+
+// RegisterFlags defines the -config, -set and -print-config flags
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{Path: os.Getenv("CONFIG_PATH")}
+	f.explicit = f.Path != ""
+	if f.Path == "" {
+		f.Path = defaultPath
+	}
+	fs.Func("config", "configuration file (default $CONFIG_PATH or "+defaultPath+")", func(path string) error {
+		f.Path, f.explicit = path, true
+		return nil
+	})
+	fs.Var(&f.Overrides, "set", "override a setting as key=value, e.g. matching.top_n=5; repeatable")
+	fs.BoolVar(&f.Print, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	return f
+}
+
+// Load loads the configuration named by the flags. A missing default file
+// is not an error: the configuration then comes from the defaults, the
+// environment and the overrides.
+func (f *Flags) Load() (*Config, error) {
+	path := f.Path
+	if !f.explicit {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			path = ""
+		}
+	}
+	return LoadConfig(path, f.Overrides...)
+}
@@ -0,0 +1,174 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// identifier matches the table names the load table may have
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validator collects the problems of a configuration by key
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key, format string, args ...interface{}) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) required(key, value string) {
+	v.check(value != "", key, "is required")
+}
+
+func (v *validator) addr(key, value string) {
+	if _, port, err := net.SplitHostPort(value); err != nil {
+		v.check(false, key, "%q is not a host:port address", value)
+	} else {
+		n, err := strconv.Atoi(port)
+		v.check(err == nil && n >= 0 && n <= 65535, key, "%q is not a valid port", port)
+	}
+}
+
+// file checks that a configured file can be read
+func (v *validator) file(key, path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		v.check(false, key, "%v", err)
+		return
+	}
+	f.Close()
+}
+
+func (v *validator) nonNegative(key string, value float64) {
+	v.check(value >= 0, key, "must not be negative, got %v", value)
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.check(false, key, "must be one of %v, got %q", allowed, value)
+}
+
+// Validate checks every setting and reports all problems at once, each
+// prefixed with its key. Matching strategies, clustering and survivorship
+// rules are further checked by the matcher when the options are built.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.required("db_creds.host", c.DBCreds.Host)
+	v.required("db_creds.username", c.DBCreds.Username)
+	v.required("db_creds.database", c.DBCreds.Database)
+	port, err := strconv.Atoi(c.DBCreds.Port)
+	v.check(err == nil && port > 0 && port <= 65535, "db_creds.port", "%q is not a valid port", c.DBCreds.Port)
+	v.check(identifier.MatchString(c.DBCreds.LoadTable), "db_creds.load_table", "%q is not a table name", c.DBCreds.LoadTable)
+
+	v.addr("server.addr", c.Server.Addr)
+	v.nonNegative("server.read_header_timeout", float64(c.Server.ReadHeaderTimeout))
+	v.nonNegative("server.read_timeout", float64(c.Server.ReadTimeout))
+	v.nonNegative("server.write_timeout", float64(c.Server.WriteTimeout))
+	v.nonNegative("server.idle_timeout", float64(c.Server.IdleTimeout))
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive, got %v", c.Server.ShutdownTimeout)
+	v.check((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""), "server.tls_cert_file", "must be set together with server.tls_key_file")
+	v.file("server.tls_cert_file", c.Server.TLSCertFile)
+	v.file("server.tls_key_file", c.Server.TLSKeyFile)
+	if c.GRPC.Addr != "" {
+		v.addr("grpc.addr", c.GRPC.Addr)
+	}
+
+	v.check(c.Pipeline.Workers > 0, "pipeline.workers", "must be positive, got %d", c.Pipeline.Workers)
+	v.required("embedding.python", c.Embedding.Python)
+	v.required("embedding.script_path", c.Embedding.ScriptPath)
+
+	v.check(c.Matching.TopN > 0, "matching.top_n", "must be positive, got %d", c.Matching.TopN)
+	v.check(c.Matching.CandidateLimit > 0, "matching.candidate_limit", "must be positive, got %d", c.Matching.CandidateLimit)
+	v.check(c.Matching.MaxDistance > 0 && c.Matching.MaxDistance <= 2, "matching.max_distance", "must be in (0, 2], got %v", c.Matching.MaxDistance)
+	v.check(c.Matching.MinScore >= 0 && c.Matching.MinScore <= 100, "matching.min_score", "must be between 0 and 100, got %v", c.Matching.MinScore)
+	v.nonNegative("matching.min_tfidf_score", c.Matching.MinTfidfScore)
+
+	v.nonNegative("scoring.similarity", c.Scoring.Similarity)
+	v.nonNegative("scoring.tfidf", c.Scoring.Tfidf)
+	v.nonNegative("scoring.first_name", c.Scoring.FirstName)
+	v.nonNegative("scoring.last_name", c.Scoring.LastName)
+	v.nonNegative("scoring.street", c.Scoring.Street)
+	v.nonNegative("scoring.city", c.Scoring.City)
+	v.nonNegative("scoring.phone_number", c.Scoring.PhoneNumber)
+	v.nonNegative("scoring.zip_code", c.Scoring.ZipCode)
+	v.nonNegative("scoring.bin_key_match", c.Scoring.BinKeyMatch)
+
+	v.nonNegative("vector_index.ef_search", float64(c.VectorIndex.EfSearch))
+	v.nonNegative("vector_index.probes", float64(c.VectorIndex.Probes))
+	v.nonNegative("vector_index.max_scan_tuples", float64(c.VectorIndex.MaxScanTuples))
+	if c.VectorIndex.Method != "" {
+		v.oneOf("vector_index.method", c.VectorIndex.Method, "hnsw", "ivfflat", "none")
+	}
+	v.check(c.Clustering.MinScore >= 0 && c.Clustering.MinScore <= 100, "clustering.min_score", "must be between 0 and 100, got %v", c.Clustering.MinScore)
+	v.check(c.Clustering.MinDensity >= 0 && c.Clustering.MinDensity <= 1, "clustering.min_density", "must be between 0 and 1, got %v", c.Clustering.MinDensity)
+
+	for i, k := range c.Auth.APIKeys {
+		key := fmt.Sprintf("auth.api_keys[%d]", i)
+		v.required(key+".subject", k.Subject)
+		v.check(len(k.KeyHash) == 64, key+".key_hash", "must be a hex SHA-256 hash")
+	}
+
+	v.nonNegative("limits.requests_per_second", c.Limits.RequestsPerSecond)
+	v.nonNegative("limits.burst", float64(c.Limits.Burst))
+	v.nonNegative("limits.max_concurrent_batches", float64(c.Limits.MaxConcurrentBatches))
+	v.nonNegative("limits.max_upload_bytes", float64(c.Limits.MaxUploadBytes))
+	v.nonNegative("limits.max_upload_rows", float64(c.Limits.MaxUploadRows))
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "", "none", "stdout", "otlp")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	if c.Tracing.Exporter == "otlp" {
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+	}
+
+	v.oneOf("logging.level", c.Logging.Level, "debug", "info", "warn", "error")
+	v.oneOf("logging.format", c.Logging.Format, "json", "text")
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(v.errs...))
+}
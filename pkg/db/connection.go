@@ -33,6 +33,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/TFMV/AddressMatchPro/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -44,7 +45,7 @@ type DBCreds struct {
 	Database string
 }
 
-// NewConnection creates a new database connection pool
+// NewConnection creates a new database connection pool whose queries are traced
 func NewConnection(creds DBCreds) (*pgxpool.Pool, error) {
 	databaseUrl := fmt.Sprintf(
 		"postgresql://%s:%s@%s:%s/%s",
@@ -59,6 +60,7 @@ func NewConnection(creds DBCreds) (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse DATABASE_URL: %v", err)
 	}
+	config.ConnConfig.Tracer = tracing.QueryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
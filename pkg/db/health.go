@@ -0,0 +1,98 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CandidateSpaceTables hold the run 0 data every match query reads
+var CandidateSpaceTables = []string{"customer_keys", "customer_tokens", "tokens_idf", "customer_vector_embedding"}
+
+// RequiredTables are the tables and run 0 partitions matching needs
+func RequiredTables() []string {
+	tables := []string{"customer_matching", "reference_entities", "runs", "match_results"}
+	for _, table := range CandidateSpaceTables {
+		tables = append(tables, table, table+"_run_0")
+	}
+	return tables
+}
+
+// CheckVectorExtension verifies that the pgvector extension is installed
+func CheckVectorExtension(ctx context.Context, pool *pgxpool.Pool) error {
+	var version string
+	err := pool.QueryRow(ctx, "SELECT extversion FROM pg_extension WHERE extname = 'vector'").Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("the vector extension is not installed")
+	}
+	return err
+}
+
+// CheckTables verifies that the tables exist, and names the missing ones
+func CheckTables(ctx context.Context, pool *pgxpool.Pool, tables []string) error {
+	rows, err := pool.Query(ctx, "SELECT t FROM unnest($1::TEXT[]) AS t WHERE to_regclass(quote_ident(t)) IS NULL", tables)
+	if err != nil {
+		return err
+	}
+	missing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CheckCandidateSpace verifies that run 0 has rows in every candidate space
+// table, and names the empty ones
+func CheckCandidateSpace(ctx context.Context, pool *pgxpool.Pool) error {
+	var empty []string
+	for _, table := range CandidateSpaceTables {
+		var exists bool
+		query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", pgx.Identifier{table + "_run_0"}.Sanitize())
+		if err := pool.QueryRow(ctx, query).Scan(&exists); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		if !exists {
+			empty = append(empty, table)
+		}
+	}
+	if len(empty) > 0 {
+		return fmt.Errorf("run 0 has no rows in %s", strings.Join(empty, ", "))
+	}
+	return nil
+}
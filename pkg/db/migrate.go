@@ -0,0 +1,291 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package db
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock key held while migrations are applied,
+// so that several instances starting at once do not race each other.
+const migrationLockID = 7209341151
+
+// ErrSchemaOutOfDate is returned by CheckSchema when the database is behind
+// the migrations embedded in the binary.
+var ErrSchemaOutOfDate = errors.New("database schema is out of date")
+
+// Migration is a single numbered schema change with its up and down scripts
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migrations returns the migrations embedded in the binary, ordered by version
+func Migrations() ([]Migration, error) {
+	return ParseMigrations(migrationFiles, "migrations")
+}
+
+// ParseMigrations reads NNNN_name.up.sql / NNNN_name.down.sql pairs from dir.
+// Versions must start at 1 and be contiguous, and every version needs both scripts.
+func ParseMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %v", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		parts := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(parts[1])
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read migration %s: %v", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		} else if m.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s is missing its up or down script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous from 1, found %d at position %d", m.Version, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// LatestVersion returns the highest embedded migration version
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
+
+// SchemaVersion returns the version recorded in schema_migrations, or 0 if
+// no migration has been applied yet. It only reads, so it is safe to call
+// from probes: a missing schema_migrations table means version 0.
+func SchemaVersion(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	var exists bool
+	if err := pool.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return 0, fmt.Errorf("unable to read schema version: %v", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+	var version int
+	err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read schema version: %v", err)
+	}
+	return version, nil
+}
+
+// CheckSchema verifies that the database schema matches the embedded
+// migrations. It wraps ErrSchemaOutOfDate when migrations are pending.
+func CheckSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	current, err := SchemaVersion(ctx, pool)
+	if err != nil {
+		return err
+	}
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	if current < latest {
+		return fmt.Errorf("%w: at version %d, binary expects %d", ErrSchemaOutOfDate, current, latest)
+	}
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary (%d)", current, latest)
+	}
+	return nil
+}
+
+// Migrate applies every pending up migration in order, each in its own
+// transaction, and returns the resulting schema version
+func Migrate(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	return migrateTo(ctx, pool, -1)
+}
+
+// MigrateTo moves the schema up or down to the given version
+func MigrateTo(ctx context.Context, pool *pgxpool.Pool, target int) (int, error) {
+	if target < 0 {
+		return 0, fmt.Errorf("invalid target version %d", target)
+	}
+	return migrateTo(ctx, pool, target)
+}
+
+func migrateTo(ctx context.Context, pool *pgxpool.Pool, target int) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	if target < 0 {
+		target = len(migrations)
+	}
+	if target > len(migrations) {
+		return 0, fmt.Errorf("target version %d is beyond latest migration %d", target, len(migrations))
+	}
+
+	if err := ensureMigrationsTable(ctx, pool); err != nil {
+		return 0, err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to acquire a connection: %v", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return 0, fmt.Errorf("unable to acquire migration lock: %v", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	var current int
+	if err := conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return 0, fmt.Errorf("unable to read schema version: %v", err)
+	}
+
+	for current < target {
+		m := migrations[current]
+		slog.InfoContext(ctx, "applying migration", "version", m.Version, "name", m.Name)
+		if err := applyMigration(ctx, conn, m.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+			return current, fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+		}
+		current = m.Version
+	}
+
+	for current > target {
+		m := migrations[current-1]
+		slog.InfoContext(ctx, "reverting migration", "version", m.Version, "name", m.Name)
+		if err := applyMigration(ctx, conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1 AND name = $2", m.Version, m.Name); err != nil {
+			return current, fmt.Errorf("reverting migration %04d_%s failed: %v", m.Version, m.Name, err)
+		}
+		current = m.Version - 1
+	}
+
+	return current, nil
+}
+
+func applyMigration(ctx context.Context, conn *pgxpool.Conn, script string, record string, version int, name string) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, record, version, name); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func ensureMigrationsTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("unable to create schema_migrations table: %v", err)
+	}
+	return nil
+}
+
+// EnsureSchema is the startup check used by the binaries: with apply set it
+// brings the schema up to date, otherwise it only verifies the version
+func EnsureSchema(ctx context.Context, pool *pgxpool.Pool, apply bool) error {
+	if !apply {
+		if err := CheckSchema(ctx, pool); err != nil {
+			if errors.Is(err, ErrSchemaOutOfDate) {
+				return fmt.Errorf("%v (restart with -migrate to apply pending migrations)", err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	version, err := Migrate(ctx, pool)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "database schema up to date", "version", version)
+	return nil
+}
@@ -0,0 +1,10 @@
+-- The customers source table is left in place: it holds the upstream customer
+-- master and may have existed before the first migration was applied.
+DROP TABLE IF EXISTS runs;
+DROP TABLE IF EXISTS customer_vector_embedding;
+DROP TABLE IF EXISTS tokens_idf;
+DROP TABLE IF EXISTS customer_tokens;
+DROP TABLE IF EXISTS customer_keys;
+DROP TABLE IF EXISTS batch_match;
+DROP TABLE IF EXISTS customer_matching;
+DROP TABLE IF EXISTS reference_entities;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS entity_clusters (
+    run_id INT NOT NULL,
+    customer_id INT NOT NULL,
+    entity_id INT NOT NULL,
+    PRIMARY KEY (run_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_clusters_run_id_entity_id ON entity_clusters (run_id, entity_id);
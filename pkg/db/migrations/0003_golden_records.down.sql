@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS golden_record_lineage;
+DROP TABLE IF EXISTS golden_records;
+ALTER TABLE customer_matching DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE customer_matching DROP COLUMN IF EXISTS source_system;
@@ -0,0 +1,28 @@
+-- Provenance used by the survivorship rules
+ALTER TABLE customer_matching ADD COLUMN IF NOT EXISTS source_system TEXT;
+ALTER TABLE customer_matching ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS golden_records (
+    run_id INT NOT NULL,
+    entity_id INT NOT NULL,
+    first_name TEXT,
+    last_name TEXT,
+    phone_number TEXT,
+    street TEXT,
+    city TEXT,
+    state TEXT,
+    zip_code TEXT,
+    member_count INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (run_id, entity_id)
+);
+
+CREATE TABLE IF NOT EXISTS golden_record_lineage (
+    run_id INT NOT NULL,
+    entity_id INT NOT NULL,
+    field_name TEXT NOT NULL,
+    field_value TEXT,
+    source_customer_id INT NOT NULL,
+    rule TEXT NOT NULL,
+    PRIMARY KEY (run_id, entity_id, field_name)
+);
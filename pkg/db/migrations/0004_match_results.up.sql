@@ -0,0 +1,37 @@
+-- Partitions are created per run as results are stored
+CREATE TABLE IF NOT EXISTS match_results (
+    run_id INT NOT NULL,
+    strategy TEXT NOT NULL,
+    input_customer_id INT NOT NULL,
+    input_run_id INT NOT NULL,
+    input_first_name TEXT,
+    input_last_name TEXT,
+    input_street TEXT,
+    input_city TEXT,
+    input_state TEXT,
+    input_zip_code TEXT,
+    input_phone_number TEXT,
+    candidate_customer_id INT NOT NULL,
+    candidate_run_id INT NOT NULL,
+    candidate_first_name TEXT,
+    candidate_last_name TEXT,
+    candidate_street TEXT,
+    candidate_city TEXT,
+    candidate_state TEXT,
+    candidate_zip_code TEXT,
+    candidate_phone_number TEXT,
+    similarity FLOAT8,
+    bin_key_match BOOLEAN,
+    tfidf_score FLOAT8,
+    rank INT,
+    score FLOAT8 NOT NULL,
+    trigram_cosine_first_name FLOAT8,
+    trigram_cosine_last_name FLOAT8,
+    trigram_cosine_street FLOAT8,
+    trigram_cosine_city FLOAT8,
+    trigram_cosine_phone_number FLOAT8,
+    trigram_cosine_zip_code FLOAT8,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) PARTITION BY LIST (run_id);
+
+CREATE INDEX IF NOT EXISTS idx_match_results_run_id_input_customer_id ON match_results (run_id, input_customer_id);
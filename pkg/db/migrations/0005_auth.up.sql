@@ -0,0 +1,10 @@
+-- Hashed API keys and the identity that created each run
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_hash TEXT PRIMARY KEY,
+    subject TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS created_by TEXT;
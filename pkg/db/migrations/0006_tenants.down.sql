@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_runs_tenant_id;
+DROP INDEX IF EXISTS idx_customer_vector_embedding_tenant_id_run_id;
+DROP INDEX IF EXISTS idx_tokens_idf_tenant_id_run_id;
+DROP INDEX IF EXISTS idx_customer_tokens_tenant_id_run_id_ngram_token;
+DROP INDEX IF EXISTS idx_customer_keys_tenant_id_run_id_binary_key;
+DROP INDEX IF EXISTS idx_customer_matching_tenant_id_run_id;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE runs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE customer_vector_embedding DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tokens_idf DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE customer_tokens DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE customer_keys DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE customer_matching DROP CONSTRAINT IF EXISTS customer_matching_pkey;
+ALTER TABLE customer_matching DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE customer_matching ADD PRIMARY KEY (customer_id, run_id);
@@ -0,0 +1,19 @@
+-- Every record, derived row and run belongs to a tenant. Existing data and
+-- callers that name no tenant use 'default'.
+ALTER TABLE customer_matching ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE customer_matching DROP CONSTRAINT IF EXISTS customer_matching_pkey;
+ALTER TABLE customer_matching ADD PRIMARY KEY (tenant_id, customer_id, run_id);
+
+ALTER TABLE customer_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE customer_tokens ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE tokens_idf ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE customer_vector_embedding ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_customer_matching_tenant_id_run_id ON customer_matching (tenant_id, run_id);
+CREATE INDEX IF NOT EXISTS idx_customer_keys_tenant_id_run_id_binary_key ON customer_keys (tenant_id, run_id, binary_key);
+CREATE INDEX IF NOT EXISTS idx_customer_tokens_tenant_id_run_id_ngram_token ON customer_tokens (tenant_id, run_id, ngram_token, entity_type_id);
+CREATE INDEX IF NOT EXISTS idx_tokens_idf_tenant_id_run_id ON tokens_idf (tenant_id, run_id);
+CREATE INDEX IF NOT EXISTS idx_customer_vector_embedding_tenant_id_run_id ON customer_vector_embedding (tenant_id, run_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_runs_tenant_id ON runs (tenant_id);
@@ -0,0 +1,3 @@
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_pkey;
+ALTER TABLE customers DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE customers ADD PRIMARY KEY (customer_id);
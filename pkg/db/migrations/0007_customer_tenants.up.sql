@@ -0,0 +1,5 @@
+-- The customer master belongs to a tenant too, so each tenant's candidate
+-- space is built from its own customers. Existing customers belong to 'default'.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_pkey;
+ALTER TABLE customers ADD PRIMARY KEY (tenant_id, customer_id);
@@ -0,0 +1,61 @@
+-- Only the returned candidates are kept, each run in its own partition again
+ALTER TABLE match_results RENAME TO match_results_by_hash;
+DROP INDEX IF EXISTS idx_match_results_run_id_input_customer_id;
+
+CREATE TABLE match_results (
+    run_id INT NOT NULL,
+    strategy TEXT NOT NULL,
+    input_customer_id INT NOT NULL,
+    input_run_id INT NOT NULL,
+    input_first_name TEXT,
+    input_last_name TEXT,
+    input_street TEXT,
+    input_city TEXT,
+    input_state TEXT,
+    input_zip_code TEXT,
+    input_phone_number TEXT,
+    candidate_customer_id INT NOT NULL,
+    candidate_run_id INT NOT NULL,
+    candidate_first_name TEXT,
+    candidate_last_name TEXT,
+    candidate_street TEXT,
+    candidate_city TEXT,
+    candidate_state TEXT,
+    candidate_zip_code TEXT,
+    candidate_phone_number TEXT,
+    similarity FLOAT8,
+    bin_key_match BOOLEAN,
+    tfidf_score FLOAT8,
+    rank INT,
+    score FLOAT8 NOT NULL,
+    trigram_cosine_first_name FLOAT8,
+    trigram_cosine_last_name FLOAT8,
+    trigram_cosine_street FLOAT8,
+    trigram_cosine_city FLOAT8,
+    trigram_cosine_phone_number FLOAT8,
+    trigram_cosine_zip_code FLOAT8,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) PARTITION BY LIST (run_id);
+
+DO $$
+DECLARE
+    id INT;
+BEGIN
+    FOR id IN SELECT DISTINCT run_id FROM match_results_by_hash LOOP
+        EXECUTE format('CREATE TABLE match_results_run_%s PARTITION OF match_results FOR VALUES IN (%s)', id, id);
+    END LOOP;
+END $$;
+
+INSERT INTO match_results
+SELECT run_id, strategy, input_customer_id, input_run_id, input_first_name, input_last_name, input_street,
+       input_city, input_state, input_zip_code, input_phone_number,
+       candidate_customer_id, candidate_run_id, candidate_first_name, candidate_last_name, candidate_street,
+       candidate_city, candidate_state, candidate_zip_code, candidate_phone_number,
+       similarity, bin_key_match, tfidf_score, rank, score,
+       trigram_cosine_first_name, trigram_cosine_last_name, trigram_cosine_street,
+       trigram_cosine_city, trigram_cosine_phone_number, trigram_cosine_zip_code, created_at
+FROM match_results_by_hash
+WHERE returned;
+DROP TABLE match_results_by_hash;
+
+CREATE INDEX IF NOT EXISTS idx_match_results_run_id_input_customer_id ON match_results (run_id, input_customer_id);
@@ -0,0 +1,62 @@
+-- Runs share a fixed set of hash partitions instead of one partition each,
+-- and every scored candidate is stored with whether it was returned. Results
+-- stored before were all returned.
+ALTER TABLE match_results RENAME TO match_results_by_run;
+DROP INDEX IF EXISTS idx_match_results_run_id_input_customer_id;
+
+CREATE TABLE match_results (
+    run_id INT NOT NULL,
+    strategy TEXT NOT NULL,
+    input_customer_id INT NOT NULL,
+    input_run_id INT NOT NULL,
+    input_first_name TEXT,
+    input_last_name TEXT,
+    input_street TEXT,
+    input_city TEXT,
+    input_state TEXT,
+    input_zip_code TEXT,
+    input_phone_number TEXT,
+    candidate_customer_id INT NOT NULL,
+    candidate_run_id INT NOT NULL,
+    candidate_first_name TEXT,
+    candidate_last_name TEXT,
+    candidate_street TEXT,
+    candidate_city TEXT,
+    candidate_state TEXT,
+    candidate_zip_code TEXT,
+    candidate_phone_number TEXT,
+    similarity FLOAT8,
+    bin_key_match BOOLEAN,
+    tfidf_score FLOAT8,
+    rank INT,
+    score FLOAT8 NOT NULL,
+    trigram_cosine_first_name FLOAT8,
+    trigram_cosine_last_name FLOAT8,
+    trigram_cosine_street FLOAT8,
+    trigram_cosine_city FLOAT8,
+    trigram_cosine_phone_number FLOAT8,
+    trigram_cosine_zip_code FLOAT8,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    returned BOOLEAN NOT NULL DEFAULT TRUE
+) PARTITION BY HASH (run_id);
+CREATE TABLE IF NOT EXISTS match_results_p0 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 0);
+CREATE TABLE IF NOT EXISTS match_results_p1 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 1);
+CREATE TABLE IF NOT EXISTS match_results_p2 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 2);
+CREATE TABLE IF NOT EXISTS match_results_p3 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 3);
+CREATE TABLE IF NOT EXISTS match_results_p4 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 4);
+CREATE TABLE IF NOT EXISTS match_results_p5 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 5);
+CREATE TABLE IF NOT EXISTS match_results_p6 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 6);
+CREATE TABLE IF NOT EXISTS match_results_p7 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 7);
+CREATE TABLE IF NOT EXISTS match_results_p8 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 8);
+CREATE TABLE IF NOT EXISTS match_results_p9 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 9);
+CREATE TABLE IF NOT EXISTS match_results_p10 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 10);
+CREATE TABLE IF NOT EXISTS match_results_p11 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 11);
+CREATE TABLE IF NOT EXISTS match_results_p12 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 12);
+CREATE TABLE IF NOT EXISTS match_results_p13 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 13);
+CREATE TABLE IF NOT EXISTS match_results_p14 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 14);
+CREATE TABLE IF NOT EXISTS match_results_p15 PARTITION OF match_results FOR VALUES WITH (MODULUS 16, REMAINDER 15);
+
+INSERT INTO match_results SELECT *, TRUE FROM match_results_by_run;
+DROP TABLE match_results_by_run;
+
+CREATE INDEX IF NOT EXISTS idx_match_results_run_id_input_customer_id ON match_results (run_id, input_customer_id);
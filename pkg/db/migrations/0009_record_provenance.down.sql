@@ -0,0 +1,5 @@
+ALTER TABLE customer_matching ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE batch_match DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE batch_match DROP COLUMN IF EXISTS source_system;
+ALTER TABLE customers DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS source_system;
@@ -0,0 +1,11 @@
+-- Provenance for the most_recent and preferred_source survivorship rules,
+-- carried from the customer master and the load table into customer_matching.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS source_system TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+ALTER TABLE batch_match ADD COLUMN IF NOT EXISTS source_system TEXT;
+ALTER TABLE batch_match ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+-- updated_at defaulted to the load time, which says nothing about when the
+-- source record changed; NULL now means the record has no known update time.
+ALTER TABLE customer_matching ALTER COLUMN updated_at DROP DEFAULT;
+UPDATE customer_matching SET updated_at = NULL WHERE updated_at IS NOT NULL;
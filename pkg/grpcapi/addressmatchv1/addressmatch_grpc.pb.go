@@ -0,0 +1,205 @@
+// AddressMatchPro gRPC API. Messages mirror the JSON bodies of the REST API
+// under /api/v1, so both transports share one matching core.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: addressmatch/v1/addressmatch.proto
+
+package addressmatchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AddressMatch_MatchStream_FullMethodName    = "/addressmatch.v1.AddressMatch/MatchStream"
+	AddressMatch_FindDuplicates_FullMethodName = "/addressmatch.v1.AddressMatch/FindDuplicates"
+	AddressMatch_Health_FullMethodName         = "/addressmatch.v1.AddressMatch/Health"
+)
+
+// AddressMatchClient is the client API for AddressMatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AddressMatchClient interface {
+	// MatchStream matches each record sent on the stream and replies with one
+	// MatchResponse per request, carrying the request_id it answers. A request
+	// that fails is answered with an error instead of closing the stream.
+	MatchStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MatchRequest, MatchResponse], error)
+	// FindDuplicates returns one page of duplicate pairs in the candidate space
+	FindDuplicates(ctx context.Context, in *DuplicateRequest, opts ...grpc.CallOption) (*DuplicatePage, error)
+	// Health reports whether the server is up
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type addressMatchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAddressMatchClient(cc grpc.ClientConnInterface) AddressMatchClient {
+	return &addressMatchClient{cc}
+}
+
+func (c *addressMatchClient) MatchStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MatchRequest, MatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AddressMatch_ServiceDesc.Streams[0], AddressMatch_MatchStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[MatchRequest, MatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AddressMatch_MatchStreamClient = grpc.BidiStreamingClient[MatchRequest, MatchResponse]
+
+func (c *addressMatchClient) FindDuplicates(ctx context.Context, in *DuplicateRequest, opts ...grpc.CallOption) (*DuplicatePage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DuplicatePage)
+	err := c.cc.Invoke(ctx, AddressMatch_FindDuplicates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *addressMatchClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, AddressMatch_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddressMatchServer is the server API for AddressMatch service.
+// All implementations must embed UnimplementedAddressMatchServer
+// for forward compatibility.
+type AddressMatchServer interface {
+	// MatchStream matches each record sent on the stream and replies with one
+	// MatchResponse per request, carrying the request_id it answers. A request
+	// that fails is answered with an error instead of closing the stream.
+	MatchStream(grpc.BidiStreamingServer[MatchRequest, MatchResponse]) error
+	// FindDuplicates returns one page of duplicate pairs in the candidate space
+	FindDuplicates(context.Context, *DuplicateRequest) (*DuplicatePage, error)
+	// Health reports whether the server is up
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	mustEmbedUnimplementedAddressMatchServer()
+}
+
+// UnimplementedAddressMatchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAddressMatchServer struct{}
+
+func (UnimplementedAddressMatchServer) MatchStream(grpc.BidiStreamingServer[MatchRequest, MatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method MatchStream not implemented")
+}
+func (UnimplementedAddressMatchServer) FindDuplicates(context.Context, *DuplicateRequest) (*DuplicatePage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDuplicates not implemented")
+}
+func (UnimplementedAddressMatchServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedAddressMatchServer) mustEmbedUnimplementedAddressMatchServer() {}
+func (UnimplementedAddressMatchServer) testEmbeddedByValue()                      {}
+
+// UnsafeAddressMatchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AddressMatchServer will
+// result in compilation errors.
+type UnsafeAddressMatchServer interface {
+	mustEmbedUnimplementedAddressMatchServer()
+}
+
+func RegisterAddressMatchServer(s grpc.ServiceRegistrar, srv AddressMatchServer) {
+	// If the following call pancis, it indicates UnimplementedAddressMatchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AddressMatch_ServiceDesc, srv)
+}
+
+func _AddressMatch_MatchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AddressMatchServer).MatchStream(&grpc.GenericServerStream[MatchRequest, MatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AddressMatch_MatchStreamServer = grpc.BidiStreamingServer[MatchRequest, MatchResponse]
+
+func _AddressMatch_FindDuplicates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DuplicateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AddressMatchServer).FindDuplicates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AddressMatch_FindDuplicates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AddressMatchServer).FindDuplicates(ctx, req.(*DuplicateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AddressMatch_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AddressMatchServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AddressMatch_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AddressMatchServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AddressMatch_ServiceDesc is the grpc.ServiceDesc for AddressMatch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AddressMatch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "addressmatch.v1.AddressMatch",
+	HandlerType: (*AddressMatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FindDuplicates",
+			Handler:    _AddressMatch_FindDuplicates_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _AddressMatch_Health_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "MatchStream",
+			Handler:       _AddressMatch_MatchStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "addressmatch/v1/addressmatch.proto",
+}
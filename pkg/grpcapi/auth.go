@@ -0,0 +1,114 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	pb "github.com/TFMV/AddressMatchPro/pkg/grpcapi/addressmatchv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes is the scope each RPC needs; methods not listed are public
+var methodScopes = map[string]string{
+	pb.AddressMatch_MatchStream_FullMethodName:    api.ScopeMatchRead,
+	pb.AddressMatch_FindDuplicates_FullMethodName: api.ScopeMatchRead,
+}
+
+// authorize authenticates the caller of method from the request metadata,
+// which carries the same x-api-key and authorization headers as the REST API.
+// The returned context records the caller on the runs it creates.
+func authorize(ctx context.Context, method string, auths []api.Authenticator) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok || len(auths) == 0 {
+		return ctx, nil
+	}
+
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, values := range md {
+		for _, v := range values {
+			header.Add(k, v)
+		}
+	}
+
+	p, err := api.AuthenticateHeader(ctx, header, auths)
+	switch {
+	case errors.Is(err, api.ErrNoCredentials):
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	case errors.Is(err, api.ErrInvalidCredentials):
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	case err != nil:
+		return nil, status.Errorf(errorCode(ctx, err), "Failed to authenticate: %v", err)
+	}
+	if !p.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "missing scope %s", scope)
+	}
+	return matcher.WithTenant(matcher.WithCaller(ctx, p.Subject), p.Tenant), nil
+}
+
+// UnaryAuthInterceptor authenticates unary calls with auths
+func UnaryAuthInterceptor(auths ...api.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, info.FullMethod, auths)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates streaming calls with auths
+func StreamAuthInterceptor(auths ...api.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), info.FullMethod, auths)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// callerStream replaces the context of a stream with the authorized one
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,132 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package grpcapi
+
+import (
+	"github.com/TFMV/AddressMatchPro/pkg/amp"
+	pb "github.com/TFMV/AddressMatchPro/pkg/grpcapi/addressmatchv1"
+)
+
+func toMatchResult(r amp.MatchResult) *pb.MatchResult {
+	return &pb.MatchResult{
+		Input: &pb.InputRecord{
+			CustomerId:  int64(r.Input.CustomerID),
+			RunId:       int64(r.Input.RunID),
+			FirstName:   r.Input.FirstName,
+			LastName:    r.Input.LastName,
+			Street:      r.Input.Street,
+			City:        r.Input.City,
+			State:       r.Input.State,
+			ZipCode:     r.Input.ZipCode,
+			PhoneNumber: r.Input.PhoneNumber,
+		},
+		Matched:    r.Matched,
+		Candidates: toCandidates(r.Candidates),
+	}
+}
+
+func toCandidates(candidates []amp.Candidate) []*pb.Candidate {
+	result := make([]*pb.Candidate, len(candidates))
+	for i, c := range candidates {
+		result[i] = toCandidate(c)
+	}
+	return result
+}
+
+func toCandidate(c amp.Candidate) *pb.Candidate {
+	return &pb.Candidate{
+		InputCustomerId:          int64(c.InputCustomerID),
+		InputRunId:               int64(c.InputRunID),
+		InputFirstName:           c.InputFirstName,
+		InputLastName:            c.InputLastName,
+		InputStreet:              c.InputStreet,
+		InputCity:                c.InputCity,
+		InputState:               c.InputState,
+		InputZipCode:             c.InputZipCode,
+		InputPhoneNumber:         c.InputPhoneNumber,
+		CandidateCustomerId:      int64(c.CandidateCustomerID),
+		CandidateRunId:           int64(c.CandidateRunID),
+		CandidateFirstName:       c.CandidateFirstName,
+		CandidateLastName:        c.CandidateLastName,
+		CandidateStreet:          c.CandidateStreet,
+		CandidateCity:            c.CandidateCity,
+		CandidateState:           c.CandidateState,
+		CandidateZipCode:         c.CandidateZipCode,
+		CandidatePhoneNumber:     c.CandidatePhoneNumber,
+		Similarity:               c.Similarity,
+		BinKeyMatch:              c.BinKeyMatch,
+		TfidfScore:               c.TfidfScore,
+		Rank:                     int32(c.Rank),
+		Score:                    c.Score,
+		TrigramCosineFirstName:   c.TrigramCosineFirstName,
+		TrigramCosineLastName:    c.TrigramCosineLastName,
+		TrigramCosineStreet:      c.TrigramCosineStreet,
+		TrigramCosineCity:        c.TrigramCosineCity,
+		TrigramCosinePhoneNumber: c.TrigramCosinePhoneNumber,
+		TrigramCosineZipCode:     c.TrigramCosineZipCode,
+		Explanation:              toExplanation(c.Explanation),
+	}
+}
+
+func toExplanation(e *amp.Explanation) *pb.Explanation {
+	if e == nil {
+		return nil
+	}
+	features := make([]*pb.FeatureContribution, len(e.Features))
+	for i, f := range e.Features {
+		features[i] = &pb.FeatureContribution{
+			Feature:      f.Feature,
+			Value:        f.Value,
+			Weight:       f.Weight,
+			Contribution: f.Contribution,
+		}
+	}
+	return &pb.Explanation{
+		Strategy:  e.Strategy,
+		Blocking:  e.Blocking,
+		Features:  features,
+		RawScore:  e.RawScore,
+		Input:     toStandardizedRecord(e.Input),
+		Candidate: toStandardizedRecord(e.Candidate),
+	}
+}
+
+func toStandardizedRecord(r amp.StandardizedRecord) *pb.StandardizedRecord {
+	return &pb.StandardizedRecord{
+		FirstName:   r.FirstName,
+		LastName:    r.LastName,
+		Street:      r.Street,
+		Unit:        r.Unit,
+		City:        r.City,
+		State:       r.State,
+		ZipCode:     r.ZipCode,
+		PhoneNumber: r.PhoneNumber,
+	}
+}
@@ -0,0 +1,115 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+package grpcapi
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// clientKey names the token bucket of the caller of ctx like the REST API
+// does, so that a client shares one bucket across both APIs
+func clientKey(ctx context.Context) string {
+	if caller := matcher.CallerFromContext(ctx); caller != "" {
+		return api.ClientKey(matcher.TenantFromContext(ctx), caller, "")
+	}
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return api.ClientKey("", "", ip)
+}
+
+// UnaryRateLimitInterceptor rejects unary calls of clients that have used up
+// their token bucket with ResourceExhausted and a retry-after header. It must
+// run after the auth interceptor, which identifies the caller.
+func UnaryRateLimitInterceptor(l *api.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if ok, wait := l.Allow(clientKey(ctx)); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor takes a token from the caller's bucket for every
+// message received on a stream. A client that has used up its bucket is
+// slowed down to the refill rate rather than having its stream closed. It
+// must run after the auth interceptor, which identifies the caller.
+func StreamRateLimitInterceptor(l *api.RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &limitedStream{ServerStream: ss, limiter: l, key: clientKey(ss.Context())})
+	}
+}
+
+// limitedStream waits for a token before handing over each received message
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *api.RateLimiter
+	key     string
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	for {
+		ok, wait := s.limiter.Allow(s.key)
+		if ok {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.Context().Done():
+			timer.Stop()
+			return status.FromContextError(s.Context().Err()).Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// --------------------------------------------------------------------------------
+// Author: Thomas F McGeehan V
+//
+// This file is part of a software project developed by Thomas F McGeehan V.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// For more information about the MIT License, please visit:
+// https://opensource.org/licenses/MIT
+//
+// Acknowledgment appreciated but not required.
+// --------------------------------------------------------------------------------
+
+// Package grpcapi serves the AddressMatch gRPC service defined in
+// proto/addressmatch/v1. It shares the matching core with the REST API
+// through an amp.Matcher.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/TFMV/AddressMatchPro --go-grpc_out=../.. --go-grpc_opt=module=github.com/TFMV/AddressMatchPro addressmatch/v1/addressmatch.proto
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/TFMV/AddressMatchPro/pkg/amp"
+	pb "github.com/TFMV/AddressMatchPro/pkg/grpcapi/addressmatchv1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the AddressMatch service
+type Server struct {
+	pb.UnimplementedAddressMatchServer
+	matcher  *amp.Matcher
+	defaults amp.MatchOptions
+}
+
+// NewServer returns a Server matching with m. defaults are the thresholds
+// m was built with; requests are validated against them before matching.
+func NewServer(m *amp.Matcher, defaults amp.MatchOptions) *Server {
+	return &Server{matcher: m, defaults: defaults}
+}
+
+// Health reports that the server is up
+func (s *Server) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
+	return &pb.HealthResponse{Status: "ok"}, nil
+}
+
+// MatchStream answers every request on the stream in order. Invalid or failed
+// requests are answered with an error; the stream only ends when the client
+// closes it or its context is done.
+func (s *Server) MatchStream(stream pb.AddressMatch_MatchStreamServer) error {
+	ctx := stream.Context()
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp := s.match(ctx, req)
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) match(ctx context.Context, req *pb.MatchRequest) *pb.MatchResponse {
+	resp := &pb.MatchResponse{RequestId: req.RequestId}
+
+	overrides := amp.MatchOverrides{
+		Strategy:       req.Strategy,
+		TopN:           intPtr(req.TopN),
+		MaxDistance:    req.MaxDistance,
+		CandidateLimit: intPtr(req.CandidateLimit),
+		MinScore:       req.MinScore,
+		Explain:        req.Explain,
+	}
+	if err := s.defaults.Merge(overrides).Validate(); err != nil {
+		resp.Error = &pb.Error{Code: int32(codes.InvalidArgument), Message: err.Error()}
+		return resp
+	}
+
+	result, err := s.matcher.MatchOne(ctx, amp.Record{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		PhoneNumber: req.PhoneNumber,
+		Street:      req.Street,
+		City:        req.City,
+		State:       req.State,
+		ZipCode:     req.ZipCode,
+	}, overrides)
+	if err != nil {
+		code := errorCode(ctx, err)
+		resp.Error = &pb.Error{Code: int32(code), Message: errorMessage(ctx, "match failed", code, err, "match_request_id", req.RequestId), Stage: errorStage(err)}
+		return resp
+	}
+
+	resp.Result = toMatchResult(result)
+	return resp
+}
+
+// FindDuplicates returns one page of duplicate pairs in the candidate space
+func (s *Server) FindDuplicates(ctx context.Context, req *pb.DuplicateRequest) (*pb.DuplicatePage, error) {
+	dreq := amp.DuplicateRequest{
+		MaxDistance:    req.MaxDistance,
+		CandidateLimit: intPtr(req.CandidateLimit),
+		MinScore:       req.MinScore,
+		State:          req.State,
+		ZipCode:        req.ZipCode,
+		PageSize:       int(req.PageSize),
+		After:          int(req.After),
+	}
+	if err := dreq.Options(s.defaults).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := dreq.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := s.matcher.FindDuplicates(ctx, dreq)
+	if err != nil {
+		code := errorCode(ctx, err)
+		return nil, status.Error(code, errorMessage(ctx, "Failed to find duplicates", code, err))
+	}
+
+	return &pb.DuplicatePage{
+		Pairs:     toCandidates(page.Pairs),
+		NextAfter: int64(page.NextAfter),
+		HasMore:   page.HasMore,
+	}, nil
+}
+
+// errorCode maps a failure to the gRPC code it is reported with
+func errorCode(ctx context.Context, err error) codes.Code {
+	switch {
+	case errors.Is(err, amp.ErrInvalidRequest):
+		return codes.InvalidArgument
+	case errors.Is(err, amp.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	// The driver does not always wrap the context error of an interrupted query
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return errorCode(context.Background(), ctxErr)
+	}
+	return codes.Internal
+}
+
+// errorMessage returns the message a failure is reported with. Caller errors
+// carry the error text; internal ones carry msg only, and the error text,
+// which may quote record values, is logged as the redacted error_detail.
+func errorMessage(ctx context.Context, msg string, code codes.Code, err error, attrs ...any) string {
+	if code == codes.InvalidArgument || code == codes.NotFound {
+		return err.Error()
+	}
+	attrs = append(attrs, "code", code.String(), "stage", errorStage(err), "error_detail", err.Error())
+	slog.ErrorContext(ctx, msg, attrs...)
+	return msg
+}
+
+// errorStage returns the pipeline stage err happened in, if it carries one
+func errorStage(err error) string {
+	var stageErr *amp.StageError
+	if errors.As(err, &stageErr) {
+		return stageErr.Stage
+	}
+	return ""
+}
+
+// intPtr converts an optional proto int32 to the override's *int; an unset
+// field stays nil and keeps the default
+func intPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
@@ -32,24 +32,39 @@ package utils
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
 
 	"github.com/TFMV/AddressMatchPro/internal/matcher"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrTooManyRows is returned when a CSV has more data rows than allowed
+var ErrTooManyRows = errors.New("too many rows")
+
 // CsvSource implements the pgx.CopyFromSource interface
 type CsvSource struct {
-	reader *csv.Reader
-	cols   []string
+	reader  *csv.Reader
+	cols    []string
+	rows    int
+	maxRows int
+	err     error
 }
 
 func (s *CsvSource) Next() bool {
 	record, err := s.reader.Read()
 	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		return false
+	}
+	s.rows++
+	if s.maxRows > 0 && s.rows > s.maxRows {
+		s.err = fmt.Errorf("%w: more than %d", ErrTooManyRows, s.maxRows)
 		return false
 	}
 	s.cols = record
@@ -65,62 +80,66 @@ func (s *CsvSource) Values() ([]interface{}, error) {
 }
 
 func (s *CsvSource) Err() error {
-	return nil
+	return s.err
 }
 
-// LoadCSV loads the CSV file into the specified table in the database
-func LoadCSV(pool *pgxpool.Pool, csvFilePath string) error {
-	file, err := os.Open(csvFilePath)
-	if err != nil {
-		return fmt.Errorf("error opening file: %w", err)
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
+// stagingTable is the temporary table a batch upload is copied into. Each
+// upload creates its own on its transaction's connection and drops it on
+// commit, so concurrent uploads never see each other's rows.
+const stagingTable = "batch_upload"
+
+// LoadBatch streams CSV data into a new run of the tenant of ctx and returns
+// the run's id. The rows are copied into a temporary table shaped like the
+// load table, and the run is created and filled from it in the same
+// transaction. The copy is rolled back with ErrTooManyRows as soon as the data
+// exceeds maxRows rows; a maxRows of 0 means no limit. Nothing is stored when
+// the load fails.
+func LoadBatch(ctx context.Context, pool *pgxpool.Pool, r io.Reader, loadTable string, maxRows int, description string) (int, error) {
+	reader := csv.NewReader(r)
 	headers, err := reader.Read() // Read the header
 	if err != nil {
-		return fmt.Errorf("error reading CSV header: %w", err)
+		return 0, fmt.Errorf("error reading CSV header: %w", err)
 	}
 
-	csvSource := &CsvSource{reader: reader}
+	csvSource := &CsvSource{reader: reader, maxRows: maxRows}
 
-	conn, err := pool.Acquire(context.Background())
+	tx, err := pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to acquire a connection: %w", err)
+		return 0, fmt.Errorf("error beginning transaction: %w", err)
 	}
-	defer conn.Release()
+	defer tx.Rollback(ctx)
 
-	// Load the configuration
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/config.yaml"
-	}
-	config, err := matcher.LoadConfig(configPath)
+	_, err = tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s INCLUDING ALL) ON COMMIT DROP",
+		pgx.Identifier{stagingTable}.Sanitize(), pgx.Identifier{loadTable}.Sanitize()))
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return 0, fmt.Errorf("error creating staging table: %w", err)
 	}
 
-	tx, err := conn.Begin(context.Background())
-	if err != nil {
-		return fmt.Errorf("error beginning transaction: %w", err)
-	}
-
-	copyCount, err := conn.Conn().CopyFrom(
-		context.Background(),
-		pgx.Identifier{config.DBCreds.LoadTable},
+	copyCount, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{stagingTable},
 		headers,
 		csvSource,
 	)
 	if err != nil {
-		tx.Rollback(context.Background())
-		return fmt.Errorf("error copying data to database: %w", err)
+		if csvSource.Err() != nil {
+			err = csvSource.Err()
+		}
+		return 0, fmt.Errorf("error copying data to database: %w", err)
 	}
 
-	if err := tx.Commit(context.Background()); err != nil {
-		return fmt.Errorf("error committing transaction: %w", err)
+	runID, err := matcher.CreateNewRun(ctx, tx, description)
+	if err != nil {
+		return 0, err
+	}
+	if err := matcher.InsertFromLoadTable(ctx, tx, stagingTable, runID); err != nil {
+		return 0, err
 	}
 
-	log.Printf("Copied %v rows to %s table", copyCount, config.DBCreds.LoadTable)
-	return nil
-}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
 
+	slog.InfoContext(ctx, "loaded CSV", "rows", copyCount, "run_id", runID)
+	return runID, nil
+}
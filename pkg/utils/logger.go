@@ -30,41 +30,43 @@
 package utils
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 )
 
-// Logger represents a logger instance.
+// Logger is a printf-style logger over the default slog logger. Messages
+// are logged with a component attribute naming the logger.
 type Logger struct {
-	*log.Logger
+	*slog.Logger
 }
 
 // NewLogger creates a new logger instance.
-func NewLogger(prefix string) *Logger {
+func NewLogger(component string) *Logger {
 	return &Logger{
-		Logger: log.New(os.Stdout, prefix, log.LstdFlags),
+		Logger: slog.Default().With("component", component),
 	}
 }
 
 // Info logs an informational message.
 func (l *Logger) Info(format string, v ...interface{}) {
-	l.Printf(fmt.Sprintf("[INFO] %s", format), v...)
+	l.Log(context.Background(), slog.LevelInfo, fmt.Sprintf(format, v...))
 }
 
 // Debug logs a debug message.
 func (l *Logger) Debug(format string, v ...interface{}) {
-	l.Printf(fmt.Sprintf("[DEBUG] %s", format), v...)
+	l.Log(context.Background(), slog.LevelDebug, fmt.Sprintf(format, v...))
 }
 
 // Error logs an error message.
 func (l *Logger) Error(format string, v ...interface{}) {
-	l.Printf(fmt.Sprintf("[ERROR] %s", format), v...)
+	l.Log(context.Background(), slog.LevelError, fmt.Sprintf(format, v...))
 }
 
 // Fatal logs a fatal error message and exits the program.
 func (l *Logger) Fatal(format string, v ...interface{}) {
-	l.Printf(fmt.Sprintf("[FATAL] %s", format), v...)
+	l.Log(context.Background(), slog.LevelError, fmt.Sprintf(format, v...))
 	os.Exit(1)
 }
 
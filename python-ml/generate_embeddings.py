@@ -30,32 +30,35 @@
 import spacy
 import psycopg2
 from psycopg2.extras import Json
+import os
 import sys
 
 # Check if run_id is provided as a command-line argument
-if len(sys.argv) != 2:
-    print("Usage: python generate_embeddings.py <run_id>")
+if len(sys.argv) not in (2, 3):
+    print("Usage: python generate_embeddings.py <run_id> [tenant_id]")
     sys.exit(1)
 
 run_id = int(sys.argv[1])
+tenant_id = sys.argv[2] if len(sys.argv) == 3 else "default"
 
 # Load spaCy model
 nlp = spacy.load("en_core_web_md")
 
 # Database connection
+# The matcher passes its database settings as the libpq PG* variables
 conn = psycopg2.connect(
-    dbname="tfmv",
-    user="postgres",
-    password="your_dbpassword",
-    host="localhost",
-    port="5432",
+    dbname=os.environ.get("PGDATABASE", "tfmv"),
+    user=os.environ.get("PGUSER", "postgres"),
+    password=os.environ.get("PGPASSWORD", ""),
+    host=os.environ.get("PGHOST", "localhost"),
+    port=os.environ.get("PGPORT", "5432"),
 )
 cur = conn.cursor()
 
-# Fetch customer data from customer_matching with the given run_id
+# Fetch the tenant's customer data from customer_matching with the given run_id
 cur.execute(
-    "SELECT customer_id, first_name, last_name, street, city, state, zip_code FROM customer_matching WHERE run_id = %s",
-    (run_id,),
+    "SELECT customer_id, first_name, last_name, street, city, state, zip_code FROM customer_matching WHERE run_id = %s AND tenant_id = %s",
+    (run_id, tenant_id),
 )
 customers = cur.fetchall()
 
@@ -68,10 +71,10 @@ for customer in customers:
 
     # Insert embeddings into customer_vector_embedding with the given run_id
     insert_query = """
-        INSERT INTO customer_vector_embedding (customer_id, vector_embedding, run_id)
-        VALUES (%s, %s, %s)
+        INSERT INTO customer_vector_embedding (customer_id, vector_embedding, run_id, tenant_id)
+        VALUES (%s, %s, %s, %s)
     """
-    cur.execute(insert_query, (customer_id, Json(vector_list), run_id))
+    cur.execute(insert_query, (customer_id, Json(vector_list), run_id, tenant_id))
 
 # Commit changes and close connection
 conn.commit()
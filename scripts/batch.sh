@@ -1,4 +1,4 @@
 curl -X POST "http://localhost:8080/api/v1/match" \
      -H "Content-Type: multipart/form-data" \
-     -F "file=@/Users/thomasmcgeehan/AddressMatchPro/AddressMatchPro/data/match.csv"
+     -F "file=@data/match.csv"
 
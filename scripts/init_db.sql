@@ -27,6 +27,10 @@
 -- Acknowledgment appreciated but not required.
 -- --------------------------------------------------------------------------------
 
+-- NOTE: this script drops and recreates every table and is only meant for
+-- resetting a local development database. Existing databases are upgraded by
+-- the versioned migrations in pkg/db/migrations (run a binary with -migrate).
+
 CREATE EXTENSION IF NOT EXISTS vector;
 
 -- Drop existing tables if they exist
@@ -0,0 +1,74 @@
+package matcher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/pkg/amp"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestNewMatcher(t *testing.T) {
+	// The pool connects lazily, so no database is needed to validate options
+	pool, err := pgxpool.New(context.Background(), "postgres://amp@localhost:5432/amp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	embedder := amp.PythonEmbedder{ScriptPath: "generate_embeddings.py"}
+
+	tests := []struct {
+		name    string
+		opts    amp.Options
+		wantErr bool
+	}{
+		{"Defaults", amp.Options{Pool: pool, Embedder: embedder}, false},
+		{"Custom profile", amp.Options{Pool: pool, Embedder: embedder, Profile: &amp.ScoringProfile{Street: 1}}, false},
+		{"Missing pool", amp.Options{Embedder: embedder}, true},
+		{"Missing embedder", amp.Options{Pool: pool}, true},
+		{"Unknown strategy", amp.Options{Pool: pool, Embedder: embedder, Match: amp.MatchOverrides{Strategy: "soundex"}}, true},
+		{"Unknown index method", amp.Options{Pool: pool, Embedder: embedder, Index: amp.IndexOptions{Method: "lsh"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := amp.New(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, err := m.Standardize("123 Main Street"); err != nil || got != "123 main st" {
+				t.Errorf("Standardize() = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestAmpMatchOptions(t *testing.T) {
+	defaults := amp.DefaultMatchOptions()
+
+	tests := []struct {
+		name    string
+		opts    amp.MatchOptions
+		wantErr bool
+	}{
+		{"Defaults", defaults, false},
+		{"Zero min score is applied", defaults.Merge(amp.MatchOverrides{MinScore: amp.Ptr(0.0)}), false},
+		{"Zero top_n is rejected", defaults.Merge(amp.MatchOverrides{TopN: amp.Ptr(0)}), true},
+		{"Duplicate thresholds", amp.DuplicateRequest{MaxDistance: amp.Ptr(3.0)}.Options(defaults), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if opts := defaults.Merge(amp.MatchOverrides{MinScore: amp.Ptr(0.0)}); opts.MinScore != 0 || opts.TopN != defaults.TopN {
+		t.Errorf("Merge() = %+v", opts)
+	}
+}
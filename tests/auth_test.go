@@ -0,0 +1,150 @@
+package matcher_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("test-secret")
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	auth := api.JWTAuthenticator{Secret: testSecret, Issuer: "amp"}
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		header  string
+		want    api.Principal
+		wantErr error
+	}{
+		{
+			name:   "Valid token",
+			header: "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "alice", "iss": "amp", "exp": future, "scope": "match:read batch:write"}),
+			want:   api.Principal{Subject: "alice", Scopes: []string{"match:read", "batch:write"}},
+		},
+		{
+			name:   "Tenant claim",
+			header: "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "bob", "iss": "amp", "exp": future, "scope": "match:read", "tenant": "acme"}),
+			want:   api.Principal{Subject: "bob", Scopes: []string{"match:read"}, Tenant: "acme"},
+		},
+		{"No header", "", api.Principal{}, api.ErrNoCredentials},
+		{"Other scheme", "Basic YWxpY2U6c2VjcmV0", api.Principal{}, api.ErrNoCredentials},
+		{
+			name:    "Expired",
+			header:  "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "alice", "iss": "amp", "exp": past}),
+			wantErr: api.ErrInvalidCredentials,
+		},
+		{
+			name:    "No expiry",
+			header:  "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "alice", "iss": "amp"}),
+			wantErr: api.ErrInvalidCredentials,
+		},
+		{
+			name:    "Wrong secret",
+			header:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{"sub": "alice", "iss": "amp", "exp": future}),
+			wantErr: api.ErrInvalidCredentials,
+		},
+		{
+			name:    "Wrong algorithm",
+			header:  "Bearer " + signToken(t, jwt.SigningMethodHS512, testSecret, jwt.MapClaims{"sub": "alice", "iss": "amp", "exp": future}),
+			wantErr: api.ErrInvalidCredentials,
+		},
+		{
+			name:    "Wrong issuer",
+			header:  "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "alice", "iss": "other", "exp": future}),
+			wantErr: api.ErrInvalidCredentials,
+		},
+		{
+			name:    "No subject",
+			header:  "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"iss": "amp", "exp": future}),
+			wantErr: api.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("Authorization", tt.header)
+			}
+			p, err := auth.Authenticate(context.Background(), header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (p.Subject != tt.want.Subject || p.Tenant != tt.want.Tenant || strings.Join(p.Scopes, " ") != strings.Join(tt.want.Scopes, " ")) {
+				t.Errorf("Authenticate() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := api.StaticKeys{
+		api.HashAPIKey("reader-key"): {Subject: "reader", Scopes: []string{api.ScopeMatchRead}},
+		api.HashAPIKey("loader-key"): {Subject: "loader", Scopes: []string{api.ScopeBatchWrite}},
+		api.HashAPIKey("admin-key"):  {Subject: "admin", Scopes: []string{api.ScopeAdmin}},
+	}
+	router := gin.New()
+	api.SetupRoutes(router, nil, api.Options{
+		MatchDefaults:   matcher.DefaultMatchOptions(),
+		ClusterDefaults: matcher.DefaultClusterOptions(),
+		Auth:            []api.Authenticator{api.APIKeyAuthenticator{Keys: keys}, api.JWTAuthenticator{Secret: testSecret}},
+	})
+	readerToken := signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix(), "scope": "match:read"})
+
+	// Requests that pass auth reach the handler and fail validation with 400,
+	// before the missing pool is touched
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"Health is public", http.MethodGet, "/api/v1/healthz", nil, http.StatusOK},
+		{"Missing credentials", http.MethodPost, "/api/v1/duplicates", nil, http.StatusUnauthorized},
+		{"Unknown key", http.MethodPost, "/api/v1/duplicates", http.Header{"X-Api-Key": {"nope"}}, http.StatusUnauthorized},
+		{"Invalid token", http.MethodPost, "/api/v1/duplicates", http.Header{"Authorization": {"Bearer nope"}}, http.StatusUnauthorized},
+		{"Reader key", http.MethodPost, "/api/v1/duplicates", http.Header{"X-Api-Key": {"reader-key"}}, http.StatusBadRequest},
+		{"Reader token", http.MethodPost, "/api/v1/duplicates", http.Header{"Authorization": {"Bearer " + readerToken}}, http.StatusBadRequest},
+		{"Missing scope", http.MethodPost, "/api/v1/duplicates", http.Header{"X-Api-Key": {"loader-key"}}, http.StatusForbidden},
+		{"Admin implies every scope", http.MethodPost, "/api/v1/entities/resolve", http.Header{"X-Api-Key": {"admin-key"}}, http.StatusBadRequest},
+		{"Resolve needs batch:write", http.MethodPost, "/api/v1/entities/resolve", http.Header{"X-Api-Key": {"reader-key"}}, http.StatusForbidden},
+		{"Batch upload needs batch:write", http.MethodPost, "/api/v1/match", http.Header{"X-Api-Key": {"reader-key"}, "Content-Type": {"multipart/form-data; boundary=x"}}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{"))
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
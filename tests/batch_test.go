@@ -0,0 +1,120 @@
+package matcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/db"
+	"github.com/TFMV/AddressMatchPro/pkg/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to the database named by AMP_TEST_DATABASE_URL and brings
+// its schema up to date, or skips the test when the variable is unset
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("AMP_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("AMP_TEST_DATABASE_URL is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if _, err := db.Migrate(context.Background(), pool); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return pool
+}
+
+// batchCSV builds an upload whose customer IDs start at first
+func batchCSV(first, rows int, lastName string) string {
+	var b strings.Builder
+	b.WriteString("customer_id,first_name,last_name,phone_number,street,city,state,zip_code\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&b, "%d,Jane,%s,555-0100,%d Main St,Springfield,IL,62701\n", first+i, lastName, i+1)
+	}
+	return b.String()
+}
+
+func TestLoadBatchConcurrentTenants(t *testing.T) {
+	pool := testPool(t)
+
+	tests := []struct {
+		tenant   string
+		first    int
+		lastName string
+	}{
+		{"batch-tenant-a", 1, "alpha"},
+		{"batch-tenant-b", 1001, "beta"},
+	}
+
+	const rounds, rows = 10, 200
+	var wg sync.WaitGroup
+	runs := make([][]int, len(tests))
+	errs := make(chan error, len(tests)*rounds)
+	for i, tt := range tests {
+		wg.Add(1)
+		go func(i int, tenant string, first int, lastName string) {
+			defer wg.Done()
+			ctx := matcher.WithTenant(context.Background(), tenant)
+			for r := 0; r < rounds; r++ {
+				runID, err := utils.LoadBatch(ctx, pool, strings.NewReader(batchCSV(first, rows, lastName)), "batch_match", 0, "batch test")
+				if err != nil {
+					errs <- fmt.Errorf("tenant %s: %v", tenant, err)
+					return
+				}
+				runs[i] = append(runs[i], runID)
+			}
+		}(i, tt.tenant, tt.first, tt.lastName)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for i, tt := range tests {
+		for _, runID := range runs[i] {
+			var total, own int
+			err := pool.QueryRow(context.Background(),
+				`SELECT COUNT(*), COUNT(*) FILTER (WHERE tenant_id = $2 AND last_name = $3 AND customer_id BETWEEN $4 AND $5)
+				 FROM customer_matching WHERE run_id = $1`,
+				runID, tt.tenant, tt.lastName, tt.first, tt.first+rows-1).Scan(&total, &own)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != rows || own != rows {
+				t.Errorf("run %d of %s has %d rows, %d of them its own, want %d", runID, tt.tenant, total, own, rows)
+			}
+		}
+	}
+}
+
+func TestLoadBatchTooManyRows(t *testing.T) {
+	pool := testPool(t)
+	ctx := matcher.WithTenant(context.Background(), "batch-tenant-limit")
+
+	var before int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM runs WHERE tenant_id = $1", "batch-tenant-limit").Scan(&before); err != nil {
+		t.Fatal(err)
+	}
+	_, err := utils.LoadBatch(ctx, pool, strings.NewReader(batchCSV(1, 5, "limit")), "batch_match", 4, "batch test")
+	if !errors.Is(err, utils.ErrTooManyRows) {
+		t.Fatalf("LoadBatch() error = %v, want %v", err, utils.ErrTooManyRows)
+	}
+	var after int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM runs WHERE tenant_id = $1", "batch-tenant-limit").Scan(&after); err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Errorf("failed load created %d runs", after-before)
+	}
+}
@@ -0,0 +1,213 @@
+package matcher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/TFMV/AddressMatchPro/pkg/client"
+	"github.com/gin-gonic/gin"
+)
+
+// newRouterServer serves the real router without a database, so only paths
+// that answer before touching the pool can be exercised
+func newRouterServer(t *testing.T, calls *int32) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, nil, api.Options{
+		MatchDefaults:   matcher.DefaultMatchOptions(),
+		ClusterDefaults: matcher.DefaultClusterOptions(),
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		router.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, baseURL string, maxRetries int) *client.Client {
+	c, err := client.New(client.Options{BaseURL: baseURL, MaxRetries: maxRetries, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientAgainstRouter(t *testing.T) {
+	var calls int32
+	server := newRouterServer(t, &calls)
+	c := newTestClient(t, server.URL, 3)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantStatus int
+	}{
+		{"Health", func() error { return c.Health(ctx) }, 0},
+		{"Match with unknown strategy", func() error {
+			_, err := c.Match(ctx, client.MatchRequest{FirstName: "john", Strategy: "soundex"})
+			return err
+		}, http.StatusBadRequest},
+		{"Batch with unknown strategy", func() error {
+			_, err := c.MatchBatch(ctx, strings.NewReader("customer_id,first_name\n1,john\n"), client.BatchOptions{Strategy: "soundex"})
+			return err
+		}, http.StatusBadRequest},
+		{"Duplicates with oversized page", func() error {
+			_, err := c.Duplicates(ctx, client.DuplicateRequest{PageSize: 5000})
+			return err
+		}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			err := tt.call()
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var apiErr *client.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus || apiErr.Message == "" {
+				t.Fatalf("error = %v, want APIError with status %d", err, tt.wantStatus)
+			}
+			if n := atomic.LoadInt32(&calls); n != 1 {
+				t.Errorf("client sent %d requests for a permanent failure, want 1", n)
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	match := func(c *client.Client) error {
+		_, err := c.Match(context.Background(), client.MatchRequest{FirstName: "john"})
+		return err
+	}
+	duplicates := func(c *client.Client) error {
+		_, err := c.Duplicates(context.Background(), client.DuplicateRequest{})
+		return err
+	}
+
+	tests := []struct {
+		name       string
+		call       func(*client.Client) error
+		status     int
+		retryAfter string
+		failures   int32
+		maxRetries int
+		wantCalls  int32
+		wantErr    bool
+	}{
+		{"Rate limited match recovers", match, http.StatusTooManyRequests, "", 2, 3, 3, false},
+		{"Unavailable match with Retry-After recovers", match, http.StatusServiceUnavailable, "1", 1, 3, 2, false},
+		{"Unavailable match without Retry-After is not retried", match, http.StatusServiceUnavailable, "", 1, 3, 1, true},
+		{"Gateway timeout of a match is not retried", match, http.StatusGatewayTimeout, "", 1, 3, 1, true},
+		{"Gateway timeout of a read recovers", duplicates, http.StatusGatewayTimeout, "", 2, 3, 3, false},
+		{"Gives up after max retries", match, http.StatusTooManyRequests, "", 10, 2, 3, true},
+		{"Retries disabled", match, http.StatusTooManyRequests, "", 10, -1, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`{"error":"busy"}`))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/api/v1/duplicates" {
+					w.Write([]byte(`{"pairs":[],"next_after":0,"has_more":false}`))
+					return
+				}
+				w.Write([]byte(`[{"input_customer_id":1,"candidate_customer_id":7,"score":91.5}]`))
+			}))
+			defer server.Close()
+
+			c, err := client.New(client.Options{BaseURL: server.URL, MaxRetries: tt.maxRetries, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.call(c); (err != nil) != tt.wantErr {
+				t.Fatalf("call error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n := atomic.LoadInt32(&calls); n != tt.wantCalls {
+				t.Errorf("client sent %d requests, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClientRetryAfterDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c, err := client.New(client.Options{BaseURL: server.URL, MaxBackoff: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.Match(ctx, client.MatchRequest{FirstName: "john"})
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Match() error = %v, want the 429", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Match() waited %v for a Retry-After beyond its deadline", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("client sent %d requests, want 1", n)
+	}
+}
+
+func TestClientDecodesServerResults(t *testing.T) {
+	// The server encodes engine results; the client's wire types must read them back
+	results := []matcher.MatchResult{{
+		Input:   matcher.InputRecord{CustomerID: 1, RunID: 4, Street: "123 main st"},
+		Matched: true,
+		Candidates: []matcher.Candidate{{
+			InputCustomerID: 1, CandidateCustomerID: 7, Score: 91.5, Rank: 1,
+			Explanation: &matcher.Explanation{Strategy: "vector", Input: matcher.StandardizedRecord{Unit: "2b"}},
+		}},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(results)
+	}))
+	defer server.Close()
+
+	got, err := newTestClient(t, server.URL, 0).MatchGrouped(context.Background(), client.MatchRequest{FirstName: "john"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Input.RunID != 4 || !got[0].Matched || len(got[0].Candidates) != 1 {
+		t.Fatalf("MatchGrouped() = %+v", got)
+	}
+	c := got[0].Candidates[0]
+	if c.CandidateCustomerID != 7 || c.Score != 91.5 || c.Explanation == nil || c.Explanation.Input.Unit != "2b" {
+		t.Errorf("candidate = %+v", c)
+	}
+}
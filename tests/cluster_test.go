@@ -0,0 +1,80 @@
+package matcher_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func pair(a, b int, score float64) matcher.Candidate {
+	return matcher.Candidate{InputCustomerID: a, CandidateCustomerID: b, Score: score}
+}
+
+func TestClusterRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []int
+		pairs    []matcher.Candidate
+		opts     matcher.ClusterOptions
+		expected []matcher.Cluster
+	}{
+		{
+			name:  "Transitive pairs form one entity",
+			ids:   []int{1, 2, 3, 4},
+			pairs: []matcher.Candidate{pair(1, 2, 90), pair(2, 3, 85)},
+			opts:  matcher.ClusterOptions{MinScore: 70},
+			expected: []matcher.Cluster{
+				{EntityID: 1, Members: []int{1, 2, 3}},
+				{EntityID: 4, Members: []int{4}},
+			},
+		},
+		{
+			name:  "Weak pairs do not link",
+			ids:   []int{1, 2, 3},
+			pairs: []matcher.Candidate{pair(1, 2, 90), pair(2, 3, 40)},
+			opts:  matcher.ClusterOptions{MinScore: 70},
+			expected: []matcher.Cluster{
+				{EntityID: 1, Members: []int{1, 2}},
+				{EntityID: 3, Members: []int{3}},
+			},
+		},
+		{
+			name:  "Self pairs are ignored",
+			ids:   []int{5},
+			pairs: []matcher.Candidate{pair(5, 5, 100)},
+			opts:  matcher.ClusterOptions{MinScore: 70},
+			expected: []matcher.Cluster{
+				{EntityID: 5, Members: []int{5}},
+			},
+		},
+		{
+			name:  "Sparse chain is split at its weakest link",
+			ids:   []int{1, 2, 3, 4},
+			pairs: []matcher.Candidate{pair(1, 2, 95), pair(2, 3, 72), pair(3, 4, 93)},
+			opts:  matcher.ClusterOptions{MinScore: 70, MinDensity: 0.6},
+			expected: []matcher.Cluster{
+				{EntityID: 1, Members: []int{1, 2}},
+				{EntityID: 3, Members: []int{3, 4}},
+			},
+		},
+		{
+			name:  "Dense cluster is kept whole",
+			ids:   []int{1, 2, 3},
+			pairs: []matcher.Candidate{pair(1, 2, 95), pair(2, 3, 72), pair(1, 3, 80)},
+			opts:  matcher.ClusterOptions{MinScore: 70, MinDensity: 0.6},
+			expected: []matcher.Cluster{
+				{EntityID: 1, Members: []int{1, 2, 3}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matcher.ClusterRecords(tt.ids, tt.pairs, tt.opts)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ClusterRecords() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package matcher_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/pkg/config"
+)
+
+// writeConfig writes a configuration file for the test and returns its path
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRepoConfigIsValid(t *testing.T) {
+	if _, err := config.LoadConfig("../config.yaml"); err != nil {
+		t.Fatalf("config.yaml: %v", err)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+matching:
+  top_n: 7
+  candidate_limit: 20
+server:
+  addr: ':9000'
+`)
+	t.Setenv("AMP_MATCHING_TOP_N", "5")
+	t.Setenv("AMP_SURVIVORSHIP_SOURCE_PRIORITY", "crm, web")
+
+	cfg, err := config.LoadConfig(path, "matching.candidate_limit=30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"Default without file value", cfg.DBCreds.LoadTable, "batch_match"},
+		{"File over default", cfg.Server.Addr, ":9000"},
+		{"Environment over file", cfg.Matching.TopN, 5},
+		{"Override over file", cfg.Matching.CandidateLimit, 30},
+		{"Environment list", strings.Join(cfg.Survivorship.SourcePriority, "|"), "crm|web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigOverrideErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		wantErr  string
+	}{
+		{"Unknown key", "matching.top_m=5", `unknown setting "matching.top_m"`},
+		{"Section is not a setting", "matching=5", `setting "matching" cannot be overridden`},
+		{"Wrong type", "matching.top_n=five", "not an integer"},
+		{"Not key=value", "matching.top_n", "not key=value"},
+		{"Map entry", "survivorship.fields=city", "not name=value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadConfig("", tt.override)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		wantErr  string
+	}{
+		{"Defaults are valid", "", ""},
+		{"Missing host", "db_creds.host=", "db_creds.host: is required"},
+		{"Bad port", "db_creds.port=54x", `db_creds.port: "54x" is not a valid port`},
+		{"Load table injection", "db_creds.load_table=t; DROP TABLE x", "db_creds.load_table"},
+		{"Bad server address", "server.addr=8080", "server.addr"},
+		{"No workers", "pipeline.workers=0", "pipeline.workers: must be positive"},
+		{"Negative weight", "scoring.street=-1", "scoring.street: must not be negative"},
+		{"Distance out of range", "matching.max_distance=3", "matching.max_distance"},
+		{"Negative scan tuples", "vector_index.max_scan_tuples=-1", "vector_index.max_scan_tuples: must not be negative"},
+		{"Unknown exporter", "tracing.exporter=jaeger", "tracing.exporter"},
+		{"Unknown log format", "logging.format=xml", "logging.format"},
+		{"Duration", "server.shutdown_timeout=30s", ""},
+		{"Bad duration", "server.idle_timeout=10", "not a duration"},
+		{"No shutdown timeout", "server.shutdown_timeout=0s", "server.shutdown_timeout: must be positive"},
+		{"Certificate without key", "server.tls_cert_file=../config.yaml", "must be set together with server.tls_key_file"},
+		{"Missing certificate", "server.tls_key_file=missing.pem", "server.tls_key_file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var overrides []string
+			if tt.override != "" {
+				overrides = append(overrides, tt.override)
+			}
+			_, err := config.LoadConfig("", overrides...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigReportsEveryProblem(t *testing.T) {
+	_, err := config.LoadConfig("", "pipeline.workers=0", "logging.level=loud")
+	if err == nil || !strings.Contains(err.Error(), "pipeline.workers") || !strings.Contains(err.Error(), "logging.level") {
+		t.Errorf("LoadConfig() error = %v, want both problems", err)
+	}
+}
+
+func TestConfigRejectsUnknownFileKeys(t *testing.T) {
+	if _, err := config.LoadConfig(writeConfig(t, "matching:\n  top_m: 5\n")); err == nil {
+		t.Error("LoadConfig() accepted an unknown key")
+	}
+}
+
+func TestConfigPrintRedactsSecrets(t *testing.T) {
+	cfg, err := config.LoadConfig("",
+		"db_creds.password=hunter2",
+		"auth.jwt.secret=jwt-signing-secret",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{Subject: "etl", KeyHash: strings.Repeat("ab", 32)}}
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "jwt-signing-secret", strings.Repeat("ab", 32)} {
+		if strings.Contains(out, secret) {
+			t.Errorf("printed configuration contains %q", secret)
+		}
+	}
+	if !strings.Contains(out, "subject: etl") {
+		t.Errorf("printed configuration lost the key subject:\n%s", out)
+	}
+	if cfg.DBCreds.Password != "hunter2" {
+		t.Error("Print changed the configuration")
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := config.RegisterFlags(fs)
+	path := writeConfig(t, "pipeline:\n  workers: 4\n")
+	if err := fs.Parse([]string{"-config", path, "-set", "server.addr=:9100", "-print-config"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Pipeline.Workers != 4 || cfg.Server.Addr != ":9100" || !flags.Print {
+		t.Errorf("workers = %d, addr = %q, print = %v", cfg.Pipeline.Workers, cfg.Server.Addr, flags.Print)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	flags = config.RegisterFlags(fs)
+	fs.Parse([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+	if _, err := flags.Load(); err == nil {
+		t.Error("Load() of a missing named file succeeded")
+	}
+}
@@ -0,0 +1,90 @@
+package matcher_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/logging"
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestStageError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		stage  string
+	}{
+		{"Cancelled embeddings", &matcher.StageError{Stage: matcher.StageEmbeddings, Err: context.Canceled}, context.Canceled, matcher.StageEmbeddings},
+		{"Wrapped deadline", fmt.Errorf("prepare run: %w", &matcher.StageError{Stage: matcher.StageTFIDF, Err: context.DeadlineExceeded}), context.DeadlineExceeded, matcher.StageTFIDF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.target) {
+				t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.target)
+			}
+			var stageErr *matcher.StageError
+			if !errors.As(tt.err, &stageErr) || stageErr.Stage != tt.stage {
+				t.Errorf("errors.As stage = %v, want %s", stageErr, tt.stage)
+			}
+		})
+	}
+}
+
+func TestServerErrorResponse(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, logging.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	slog.SetDefault(logger)
+
+	// Nothing listens on port 1, so every query fails with a connection error
+	pool, err := pgxpool.New(context.Background(), "postgres://amp@127.0.0.1:1/amp?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, pool, api.Options{
+		MatchDefaults:   matcher.DefaultMatchOptions(),
+		ClusterDefaults: matcher.DefaultClusterOptions(),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/runs/5/matches", nil)
+	req.Header.Set(api.RequestIDHeader, "req-500")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500 (body %s)", w.Code, w.Body.String())
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["error"] != "Failed to load match results" || body["request_id"] != "req-500" {
+		t.Errorf("body = %v, want the generic message and the request ID", body)
+	}
+	if strings.Contains(w.Body.String(), "127.0.0.1") {
+		t.Errorf("body %s leaks the error detail", w.Body.String())
+	}
+	if !strings.Contains(logs.String(), `"error_detail":"`+logging.Redacted+`"`) {
+		t.Errorf("log %s does not carry the redacted error detail", logs.String())
+	}
+}
@@ -0,0 +1,82 @@
+package matcher_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func TestExplainCandidate(t *testing.T) {
+	strategy, err := matcher.LookupStrategy("vector")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := matcher.DefaultMatchOptions()
+
+	tests := []struct {
+		name         string
+		candidate    matcher.Candidate
+		wantBlocking []string
+		wantStreet   string
+		wantUnit     string
+		wantPhone    string
+	}{
+		{
+			name: "Close vector match sharing a binary key",
+			candidate: matcher.Candidate{
+				InputStreet: "12 Main Street Apt 2B", CandidateStreet: "12 main st", InputPhoneNumber: "(555) 010-0100",
+				Similarity: 0.05, TfidfScore: 0.8, BinKeyMatch: true,
+				TrigramCosineFirstName: 1, TrigramCosineLastName: 0.9, TrigramCosineStreet: 0.7,
+				TrigramCosineCity: 1, TrigramCosineZipCode: 1,
+			},
+			wantBlocking: []string{"vector_distance", "geography", "binary_key"},
+			wantStreet:   "12 main st",
+			wantUnit:     "2b",
+			wantPhone:    "5550100100",
+		},
+		{
+			name: "Distant match without a binary key",
+			candidate: matcher.Candidate{
+				Similarity: 1,
+			},
+			wantBlocking: []string{"vector_distance", "geography"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate := tt.candidate
+			matcher.ExplainCandidate(&candidate, strategy, opts, matcher.PersonScoringProfile())
+			explanation := candidate.Explanation
+			if explanation == nil {
+				t.Fatal("ExplainCandidate() left no explanation")
+			}
+
+			sum := 0.0
+			for _, f := range explanation.Features {
+				if math.Abs(f.Contribution-f.Value*f.Weight*100) > 1e-9 {
+					t.Errorf("feature %s contribution = %v, want value*weight*100", f.Feature, f.Contribution)
+				}
+				sum += f.Contribution
+			}
+			if math.Abs(sum/100-explanation.RawScore) > 1e-9 {
+				t.Errorf("RawScore = %v, want sum of contributions %v", explanation.RawScore, sum/100)
+			}
+
+			if len(explanation.Blocking) != len(tt.wantBlocking) {
+				t.Fatalf("Blocking = %v, want %v", explanation.Blocking, tt.wantBlocking)
+			}
+			for i, prefix := range tt.wantBlocking {
+				if !strings.HasPrefix(explanation.Blocking[i], prefix) {
+					t.Errorf("Blocking[%d] = %q, want prefix %q", i, explanation.Blocking[i], prefix)
+				}
+			}
+
+			if explanation.Input.Street != tt.wantStreet || explanation.Input.Unit != tt.wantUnit || explanation.Input.PhoneNumber != tt.wantPhone {
+				t.Errorf("Input = %+v, want street %q unit %q phone %q", explanation.Input, tt.wantStreet, tt.wantUnit, tt.wantPhone)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package matcher_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/pkg/amp"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/TFMV/AddressMatchPro/pkg/grpcapi"
+	pb "github.com/TFMV/AddressMatchPro/pkg/grpcapi/addressmatchv1"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// newGRPCClient serves the gRPC API over an in-memory listener. The pool
+// connects lazily, so requests rejected before matching need no database.
+func newGRPCClient(t *testing.T, opts ...grpc.ServerOption) pb.AddressMatchClient {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://amp@localhost:5432/amp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	defaults := amp.DefaultMatchOptions()
+	m, err := amp.New(amp.Options{Pool: pool, Embedder: amp.PythonEmbedder{ScriptPath: "generate_embeddings.py"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	pb.RegisterAddressMatchServer(server, grpcapi.NewServer(m, defaults))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewAddressMatchClient(conn)
+}
+
+func TestGRPCHealth(t *testing.T) {
+	client := newGRPCClient(t)
+	resp, err := client.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "ok" {
+		t.Errorf("Health() status = %q, want ok", resp.Status)
+	}
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	limiter := api.NewRateLimiter(0.01, 1)
+	client := newGRPCClient(t,
+		grpc.ChainUnaryInterceptor(grpcapi.UnaryAuthInterceptor(), grpcapi.UnaryRateLimitInterceptor(limiter)),
+		grpc.ChainStreamInterceptor(grpcapi.StreamAuthInterceptor(), grpcapi.StreamRateLimitInterceptor(limiter)))
+
+	if _, err := client.Health(context.Background(), &pb.HealthRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The burst is spent, so the next call is turned away with a retry hint
+	var header metadata.MD
+	_, err := client.Health(context.Background(), &pb.HealthRequest{}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Health() error = %v, want ResourceExhausted", err)
+	}
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] == "" {
+		t.Errorf("retry-after header = %v, want one value", got)
+	}
+
+	// Streams share the bucket, so a message waits for a token until the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	stream, err := client.MatchStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&pb.MatchRequest{RequestId: "a", Street: "123 Main St"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.DeadlineExceeded && status.Code(err) != codes.Canceled {
+		t.Errorf("Recv() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestGRPCMatchStreamRejectsInvalidRequests(t *testing.T) {
+	client := newGRPCClient(t)
+	stream, err := client.MatchStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []*pb.MatchRequest{
+		{RequestId: "a", Street: "123 Main St", Strategy: "soundex"},
+		{RequestId: "b", Street: "123 Main St", MaxDistance: proto.Float64(3)},
+		{RequestId: "c", Street: "123 Main St", MinScore: proto.Float64(101)},
+		// A field that is set is applied as given, so zero is not the default
+		{RequestId: "d", Street: "123 Main St", TopN: proto.Int32(0)},
+	}
+	for _, req := range requests {
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every request is answered, in order, without closing the stream
+	for _, req := range requests {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() for %q: %v", req.RequestId, err)
+		}
+		if resp.RequestId != req.RequestId {
+			t.Errorf("response request_id = %q, want %q", resp.RequestId, req.RequestId)
+		}
+		if resp.Error == nil || codes.Code(resp.Error.Code) != codes.InvalidArgument {
+			t.Errorf("request %q: error = %v, want InvalidArgument", req.RequestId, resp.Error)
+		}
+	}
+}
+
+func TestGRPCFindDuplicatesValidation(t *testing.T) {
+	client := newGRPCClient(t)
+
+	tests := []struct {
+		name string
+		req  *pb.DuplicateRequest
+	}{
+		{"Page size too large", &pb.DuplicateRequest{PageSize: 100000}},
+		{"Negative cursor", &pb.DuplicateRequest{After: -1}},
+		{"Min score out of range", &pb.DuplicateRequest{MinScore: proto.Float64(150)}},
+		{"Zero candidate limit", &pb.DuplicateRequest{CandidateLimit: proto.Int32(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.FindDuplicates(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("FindDuplicates() error = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package matcher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func passCheck(context.Context) error { return nil }
+
+// readiness runs the readiness handler over the checks and decodes its response.
+// It fails the test when the response leaks a check's error.
+func readiness(t *testing.T, timeout time.Duration, checks ...api.Check) (int, api.Readiness) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", api.ReadinessHandler(timeout, checks...))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body api.Readiness
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q is not JSON: %v", w.Body.String(), err)
+	}
+	if strings.Contains(w.Body.String(), "error") || strings.Contains(w.Body.String(), "deadline") {
+		t.Errorf("response %s carries error details", w.Body.String())
+	}
+	return w.Code, body
+}
+
+func TestReadinessHandler(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		checks     []api.Check
+		wantStatus int
+		wantChecks map[string]string
+	}{
+		{"All checks pass", []api.Check{{Name: "database", Run: passCheck}, {Name: "tables", Run: passCheck}},
+			http.StatusOK, map[string]string{"database": "ok", "tables": "ok"}},
+		{"One check fails", []api.Check{{Name: "database", Run: passCheck}, {Name: "candidate_space", Run: func(context.Context) error {
+			return errors.New("run 0 has no rows in tokens_idf")
+		}}}, http.StatusServiceUnavailable, map[string]string{"database": "ok", "candidate_space": "fail"}},
+		{"Slow check times out", []api.Check{{Name: "database", Run: slow}},
+			http.StatusServiceUnavailable, map[string]string{"database": "fail"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			code, body := readiness(t, 50*time.Millisecond, tt.checks...)
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("probe took %v, want it bounded by the timeout", elapsed)
+			}
+			if code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", code, tt.wantStatus)
+			}
+			if (code == http.StatusOK) != (body.Status == "ok") {
+				t.Errorf("body status = %q with HTTP %d", body.Status, code)
+			}
+			if len(body.Checks) != len(tt.wantChecks) {
+				t.Fatalf("checks = %+v, want %d", body.Checks, len(tt.wantChecks))
+			}
+			for _, check := range body.Checks {
+				if check.Status != tt.wantChecks[check.Name] {
+					t.Errorf("check %s = %q, want %q", check.Name, check.Status, tt.wantChecks[check.Name])
+				}
+				if check.LatencyMS < 0 {
+					t.Errorf("check %s latency = %v", check.Name, check.LatencyMS)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthRoutes(t *testing.T) {
+	router := newLimitedRouter(api.Limits{})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"Liveness needs no database", "/livez", http.StatusOK},
+		{"Legacy health check", "/api/v1/healthz", http.StatusOK},
+		{"Readiness without a database", "/readyz", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s status = %d, want %d: %s", tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package matcher_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func TestSplitUnit(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expectedBase string
+		expectedUnit string
+	}{
+		{"No unit", "123 Main Street", "123 main st", ""},
+		{"Apartment", "123 Main St Apt 2B", "123 main st", "2b"},
+		{"Hash designator", "123 Main St #3C", "123 main st", "3c"},
+		{"Leading zeros and dashes", "123 Main St Unit 02-B", "123 main st", "2b"},
+		{"Building and apartment", "9 Oak Dr Bldg 4, Apt. 12", "9 oak dr", "4 12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, unit := matcher.SplitUnit(tt.input)
+			if base != tt.expectedBase || unit != tt.expectedUnit {
+				t.Errorf("SplitUnit(%q) = (%q, %q), want (%q, %q)", tt.input, base, unit, tt.expectedBase, tt.expectedUnit)
+			}
+		})
+	}
+}
+
+func TestScoreHousehold(t *testing.T) {
+	pairAt := func(inputStreet, inputLast, candidateStreet, candidateLast string) matcher.Candidate {
+		return matcher.Candidate{
+			InputFirstName: "ann", InputLastName: inputLast, InputStreet: inputStreet,
+			InputCity: "springfield", InputZipCode: "12345",
+			CandidateFirstName: "bob", CandidateLastName: candidateLast, CandidateStreet: candidateStreet,
+			CandidateCity: "springfield", CandidateZipCode: "12345",
+			BinKeyMatch: true,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		pair         matcher.Candidate
+		matchSurname bool
+		wantKept     bool
+		wantMinScore float64
+	}{
+		{"Same address, different names", pairAt("12 main st", "smith", "12 Main Street", "jones"), false, true, 99},
+		{"Same unit", pairAt("12 main st apt 2b", "smith", "12 main st #2B", "jones"), false, true, 99},
+		{"Different units", pairAt("12 main st apt 2b", "smith", "12 main st apt 3c", "smith"), false, false, 0},
+		{"Unit against no unit", pairAt("12 main st apt 2b", "smith", "12 main st", "smith"), false, false, 0},
+		{"Surname required and different", pairAt("12 main st", "smith", "12 main st", "jones"), true, false, 0},
+		{"Surname required and same", pairAt("12 main st", "smith", "12 main st", "smith"), true, true, 99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair := tt.pair
+			kept := matcher.ScoreHousehold(&pair, tt.matchSurname)
+			if kept != tt.wantKept {
+				t.Fatalf("ScoreHousehold() kept = %v, want %v", kept, tt.wantKept)
+			}
+			if kept && pair.Score < tt.wantMinScore {
+				t.Errorf("ScoreHousehold() score = %v, want at least %v", pair.Score, tt.wantMinScore)
+			}
+		})
+	}
+}
+
+func TestFindHouseholdsCapKeepsCoResidents(t *testing.T) {
+	pool := testPool(t)
+	ctx := matcher.WithTenant(context.Background(), fmt.Sprintf("household-cap-%d", time.Now().UnixNano()))
+
+	// The co-residents of apartment 99 have the lowest and the highest id, with
+	// more neighbors between them than the candidate cap
+	records := []matcher.InputRecord{{CustomerID: 1, FirstName: "John", LastName: "Doe", Street: "100 Main St Apt 99", City: "Springfield", State: "IL", ZipCode: "62701"}}
+	for id := 2; id <= 61; id++ {
+		records = append(records, matcher.InputRecord{CustomerID: id, FirstName: "Pat", LastName: fmt.Sprintf("Neighbor%d", id),
+			Street: fmt.Sprintf("100 Main St Apt %d", id), City: "Springfield", State: "IL", ZipCode: "62701"})
+	}
+	records = append(records, matcher.InputRecord{CustomerID: 62, FirstName: "Jane", LastName: "Doe", Street: "100 Main St Apt 99", City: "Springfield", State: "IL", ZipCode: "62701"})
+	if err := matcher.ReplaceCandidateRecords(ctx, pool, records); err != nil {
+		t.Fatal(err)
+	}
+
+	req := matcher.HouseholdRequest{ZipCode: "62701", CandidateLimit: matcher.Ptr(5)}
+	households, err := matcher.FindHouseholds(ctx, pool, req, req.Options(matcher.DefaultMatchOptions()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range households {
+		if h.HouseholdID == 1 && len(h.Members) == 2 && h.Members[1].CustomerID == 62 {
+			return
+		}
+	}
+	t.Errorf("FindHouseholds() = %+v, want customers 1 and 62 in one household", households)
+}
@@ -0,0 +1,172 @@
+package matcher_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := api.NewRateLimiter(1, 2)
+
+	tests := []struct {
+		name    string
+		client  string
+		allowed bool
+	}{
+		{"First of burst", "alice", true},
+		{"Second of burst", "alice", true},
+		{"Bucket empty", "alice", false},
+		{"Other client", "bob", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, wait := limiter.Allow(tt.client)
+			if ok != tt.allowed {
+				t.Fatalf("Allow(%q) = %v, want %v", tt.client, ok, tt.allowed)
+			}
+			if !ok && (wait <= 0 || wait > time.Second) {
+				t.Errorf("Allow(%q) wait = %v, want within a second", tt.client, wait)
+			}
+		})
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	limiter := api.NewRateLimiter(100, 1)
+	if ok, _ := limiter.Allow("alice"); !ok {
+		t.Fatal("first request rejected")
+	}
+	if ok, _ := limiter.Allow("alice"); ok {
+		t.Fatal("request beyond the burst allowed")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := limiter.Allow("alice"); !ok {
+		t.Error("request rejected after the bucket refilled")
+	}
+}
+
+func newLimitedRouter(limits api.Limits) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, nil, api.Options{
+		MatchDefaults:   matcher.DefaultMatchOptions(),
+		ClusterDefaults: matcher.DefaultClusterOptions(),
+		Limits:          limits,
+	})
+	return router
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	router := newLimitedRouter(api.Limits{RequestsPerSecond: 0.01, Burst: 1})
+
+	// Requests let through fail validation with 400 before the missing pool is touched
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"Within burst", "/api/v1/duplicates", http.StatusBadRequest},
+		{"Over the limit", "/api/v1/duplicates", http.StatusTooManyRequests},
+		{"Health is not limited", "/api/v1/healthz", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodPost
+			if strings.HasSuffix(tt.path, "healthz") {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(method, tt.path, strings.NewReader("{")))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code == http.StatusTooManyRequests {
+				if seconds, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || seconds < 1 {
+					t.Errorf("Retry-After = %q, want whole seconds", w.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestUploadSizeLimit(t *testing.T) {
+	router := newLimitedRouter(api.Limits{MaxUploadBytes: 1024})
+
+	tests := []struct {
+		name       string
+		strategy   string
+		header     string
+		wantStatus int
+	}{
+		// Options are read before the file, so a bad one fails fast
+		{"Within limit", "soundex", "customer_id,first_name", http.StatusBadRequest},
+		// The file is cut off while its header is still being read
+		{"Too large", "", "customer_id,first_name," + strings.Repeat("x", 2048), http.StatusRequestEntityTooLarge},
+		{"Too large field", strings.Repeat("x", 2048), "customer_id,first_name", http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			if tt.strategy != "" {
+				form.WriteField("strategy", tt.strategy)
+			}
+			file, _ := form.CreateFormFile("file", "batch.csv")
+			file.Write([]byte(tt.header + "\n1,john\n"))
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/match", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBatchFormBooleans(t *testing.T) {
+	router := newLimitedRouter(api.Limits{})
+
+	tests := []struct {
+		name  string
+		field string
+		value string
+	}{
+		{"Invalid group", "group", "yes"},
+		{"Invalid explain", "explain", "maybe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			form.WriteField(tt.field, tt.value)
+			file, _ := form.CreateFormFile("file", "batch.csv")
+			file.Write([]byte("customer_id,first_name\n1,john\n"))
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/match", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid "+tt.field) {
+				t.Errorf("status = %d, body %s; want 400 naming %s", w.Code, w.Body.String(), tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package matcher_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/logging"
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+)
+
+// logRecord logs one record with the given attributes and returns it decoded
+func logRecord(t *testing.T, cfg logging.Config, ctx context.Context, args ...any) map[string]interface{} {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.InfoContext(ctx, "test", args...)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record %q is not JSON: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestLogRedaction(t *testing.T) {
+	req := matcher.MatchRequest{FirstName: "Jane", LastName: "Doe", Street: "1 Main St", City: "Springfield", TopN: matcher.Ptr(5)}
+
+	tests := []struct {
+		name   string
+		logPII bool
+		args   []any
+		key    string
+		group  string
+		want   interface{}
+	}{
+		{"Name is masked", false, []any{"first_name", "Jane"}, "first_name", "", logging.Redacted},
+		{"Phone is masked", false, []any{"phone_number", "555-0100"}, "phone_number", "", logging.Redacted},
+		{"Input prefix is masked", false, []any{"input_street", "1 Main St"}, "input_street", "", logging.Redacted},
+		{"Candidate prefix is masked", false, []any{"candidate_last_name", "Doe"}, "candidate_last_name", "", logging.Redacted},
+		{"Empty value is kept", false, []any{"last_name", ""}, "last_name", "", ""},
+		{"Non-PII key is kept", false, []any{"city", "Springfield"}, "city", "", "Springfield"},
+		{"PII logging disables masking", true, []any{"first_name", "Jane"}, "first_name", "", "Jane"},
+		{"Match request street is masked", false, []any{"request", req}, "street", "request", logging.Redacted},
+		{"Match request city is kept", false, []any{"request", req}, "city", "request", "Springfield"},
+		{"Map entries are masked", false, []any{"body", logging.Map(map[string]interface{}{"street": "1 Main St", "top_n": 5})}, "street", "body", logging.Redacted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := logRecord(t, logging.Config{LogPII: tt.logPII}, context.Background(), tt.args...)
+			if tt.group != "" {
+				group, ok := record[tt.group].(map[string]interface{})
+				if !ok {
+					t.Fatalf("record %v has no %q group", record, tt.group)
+				}
+				record = group
+			}
+			if got := record[tt.key]; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogRequestID(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	if got := logRecord(t, logging.Config{}, ctx)["request_id"]; got != "req-1" {
+		t.Errorf("request_id = %v, want req-1", got)
+	}
+	if _, ok := logRecord(t, logging.Config{}, context.Background())["request_id"]; ok {
+		t.Error("record without a request ID in its context has request_id")
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{Level: "warn", Format: "text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept")
+	if out := buf.String(); strings.Contains(out, "dropped") || !strings.Contains(out, "msg=kept") {
+		t.Errorf("warn level text log = %q", out)
+	}
+}
+
+func TestLogConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     logging.Config
+		wantErr bool
+	}{
+		{"Defaults", logging.Config{}, false},
+		{"Debug text", logging.Config{Level: "debug", Format: "text"}, false},
+		{"Invalid level", logging.Config{Level: "verbose"}, true},
+		{"Invalid format", logging.Config{Format: "xml"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logging.New(&bytes.Buffer{}, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	router := newLimitedRouter(api.Limits{})
+
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{"Client ID is echoed", "abc-123", true},
+		{"Missing ID is generated", "", false},
+		{"ID with spaces is replaced", "abc 123", false},
+		{"Overlong ID is replaced", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/healthz", nil)
+			if tt.header != "" {
+				req.Header.Set(api.RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(api.RequestIDHeader)
+			if tt.wantSame && got != tt.header {
+				t.Errorf("request ID = %q, want %q", got, tt.header)
+			}
+			if !tt.wantSame && (got == tt.header || len(got) != 32) {
+				t.Errorf("request ID = %q, want a new 32 character ID", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func TestMatchRequestOptions(t *testing.T) {
+	defaults := matcher.DefaultMatchOptions()
+
+	opts := matcher.MatchRequest{}.Options(defaults)
+	if opts != defaults {
+		t.Errorf("Options() with empty request = %+v, want defaults %+v", opts, defaults)
+	}
+
+	opts = matcher.MatchRequest{TopN: matcher.Ptr(3), MaxDistance: matcher.Ptr(0.2), CandidateLimit: matcher.Ptr(5), MinScore: matcher.Ptr(40.0)}.Options(defaults)
+	if opts.TopN != 3 || opts.MaxDistance != 0.2 || opts.CandidateLimit != 5 || opts.MinScore != 40 {
+		t.Errorf("Options() did not apply request overrides: %+v", opts)
+	}
+	if opts.Strategy != defaults.Strategy {
+		t.Errorf("Options() strategy = %q, want %q", opts.Strategy, defaults.Strategy)
+	}
+}
+
+func TestMatchOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides matcher.MatchOverrides
+		wantErr   bool
+	}{
+		{"Zero min score is applied", matcher.MatchOverrides{MinScore: matcher.Ptr(0.0)}, false},
+		{"Zero top_n is rejected", matcher.MatchOverrides{TopN: matcher.Ptr(0)}, true},
+		{"Negative top_n is rejected", matcher.MatchOverrides{TopN: matcher.Ptr(-5)}, true},
+		{"Negative candidate limit is rejected", matcher.MatchOverrides{CandidateLimit: matcher.Ptr(-1)}, true},
+		{"Negative distance is rejected", matcher.MatchOverrides{MaxDistance: matcher.Ptr(-0.1)}, true},
+		{"Negative min score is rejected", matcher.MatchOverrides{MinScore: matcher.Ptr(-1.0)}, true},
+		{"Negative min TF/IDF score is rejected", matcher.MatchOverrides{MinTfidfScore: matcher.Ptr(-0.1)}, true},
+		{"Negative ef_search is rejected", matcher.MatchOverrides{EfSearch: matcher.Ptr(-1)}, true},
+		{"Negative max_scan_tuples is rejected", matcher.MatchOverrides{MaxScanTuples: matcher.Ptr(-1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := matcher.DefaultMatchOptions().Merge(tt.overrides)
+			if err := opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.overrides.MinScore != nil && opts.MinScore != *tt.overrides.MinScore {
+				t.Errorf("MinScore = %v, want %v", opts.MinScore, *tt.overrides.MinScore)
+			}
+		})
+	}
+}
+
+func TestMatchOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*matcher.MatchOptions)
+		wantErr bool
+	}{
+		{"Defaults", func(o *matcher.MatchOptions) {}, false},
+		{"Unknown strategy", func(o *matcher.MatchOptions) { o.Strategy = "soundex" }, true},
+		{"Distance out of range", func(o *matcher.MatchOptions) { o.MaxDistance = 3 }, true},
+		{"Zero candidate limit", func(o *matcher.MatchOptions) { o.CandidateLimit = 0 }, true},
+		{"Score above 100", func(o *matcher.MatchOptions) { o.MinScore = 101 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := matcher.DefaultMatchOptions()
+			tt.modify(&opts)
+			if err := opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDuplicateRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     matcher.DuplicateRequest
+		wantErr bool
+	}{
+		{"Defaults", matcher.DuplicateRequest{}, false},
+		{"Scoped page", matcher.DuplicateRequest{State: "pr", PageSize: 500, After: 1200}, false},
+		{"Page too large", matcher.DuplicateRequest{PageSize: 5000}, true},
+		{"Negative cursor", matcher.DuplicateRequest{After: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
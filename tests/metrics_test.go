@@ -0,0 +1,44 @@
+package matcher_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	router := newLimitedRouter(api.Limits{})
+	for _, path := range []string{"/api/v1/healthz", "/api/v1/entities/not-a-number", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d", w.Code)
+	}
+	body := w.Body.String()
+
+	tests := []struct {
+		name   string
+		series string
+	}{
+		{"Static route", `amp_http_requests_total{method="GET",route="/api/v1/healthz",status="200"}`},
+		{"Route pattern instead of path", `amp_http_requests_total{method="GET",route="/api/v1/entities/:customer_id",status="400"}`},
+		{"Unknown path", `amp_http_requests_total{method="GET",route="unmatched",status="404"}`},
+		{"Latency histogram", `amp_http_request_duration_seconds_bucket{method="GET",route="/api/v1/healthz",status="200",le="+Inf"}`},
+		{"Latency bucket at the write timeout", `amp_http_request_duration_seconds_bucket{method="GET",route="/api/v1/healthz",status="200",le="900"}`},
+		{"Runtime metrics", `go_goroutines`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(body, tt.series) {
+				t.Errorf("/metrics has no %s", tt.series)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package matcher_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/TFMV/AddressMatchPro/pkg/db"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := db.Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Migrations() returned no migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d", i, m.Version)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("migration %04d_%s is missing a script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestParseMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ordered pairs",
+			files: fstest.MapFS{
+				"m/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+				"m/0002_second.down.sql": {Data: []byte("SELECT -2")},
+				"m/0001_first.up.sql":    {Data: []byte("SELECT 1")},
+				"m/0001_first.down.sql":  {Data: []byte("SELECT -1")},
+			},
+			want: 2,
+		},
+		{
+			name: "Missing down script",
+			files: fstest.MapFS{
+				"m/0001_first.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Gap in versions",
+			files: fstest.MapFS{
+				"m/0001_first.up.sql":   {Data: []byte("SELECT 1")},
+				"m/0001_first.down.sql": {Data: []byte("SELECT -1")},
+				"m/0003_third.up.sql":   {Data: []byte("SELECT 3")},
+				"m/0003_third.down.sql": {Data: []byte("SELECT -3")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid file name",
+			files: fstest.MapFS{
+				"m/first.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := db.ParseMigrations(tt.files, "m")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(migrations) != tt.want {
+				t.Fatalf("ParseMigrations() returned %d migrations, want %d", len(migrations), tt.want)
+			}
+			if migrations[0].Name != "first" || migrations[0].Up != "SELECT 1" {
+				t.Errorf("ParseMigrations() first migration = %+v", migrations[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package matcher_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+const pprlSecret = "0123456789abcdef-shared"
+
+func newEncoder(t *testing.T, secret string) matcher.CLKEncoder {
+	t.Helper()
+	encoder, err := matcher.NewCLKEncoder([]byte(secret), matcher.DefaultFilterBits, matcher.DefaultFilterHashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoder
+}
+
+func TestNewCLKEncoderValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		bits    int
+		hashes  int
+		wantErr bool
+	}{
+		{"Defaults", pprlSecret, matcher.DefaultFilterBits, matcher.DefaultFilterHashes, false},
+		{"Short secret", "secret", matcher.DefaultFilterBits, matcher.DefaultFilterHashes, true},
+		{"Bits not a multiple of 8", pprlSecret, 1001, matcher.DefaultFilterHashes, true},
+		{"No hashes", pprlSecret, matcher.DefaultFilterBits, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := matcher.NewCLKEncoder([]byte(tt.secret), tt.bits, tt.hashes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCLKEncoder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBloomFilterDice(t *testing.T) {
+	encoder := newEncoder(t, pprlSecret)
+	other := newEncoder(t, "another-secret-of-16-bytes")
+
+	tests := []struct {
+		name    string
+		a, b    matcher.BloomFilter
+		wantMin float64
+		wantMax float64
+	}{
+		{"Identical values", encoder.EncodeField("last_name", "Baldwin"), encoder.EncodeField("last_name", "baldwin"), 1, 1},
+		{"Similar values", encoder.EncodeField("last_name", "Baldwin"), encoder.EncodeField("last_name", "Baldwyn"), 0.6, 0.95},
+		{"Different values", encoder.EncodeField("last_name", "Baldwin"), encoder.EncodeField("last_name", "Ortiz"), 0, 0.3},
+		{"Standardized streets", encoder.EncodeField("street", "12 Elm Street"), encoder.EncodeField("street", "12 Elm St"), 1, 1},
+		{"Field name is part of the key", encoder.EncodeField("first_name", "Mary"), encoder.EncodeField("last_name", "Mary"), 0, 0.3},
+		{"Other secret", encoder.EncodeField("last_name", "Baldwin"), other.EncodeField("last_name", "Baldwin"), 0, 0.3},
+		{"Empty value", encoder.EncodeField("last_name", ""), encoder.EncodeField("last_name", ""), 0, 0},
+		{"Different sizes", matcher.BloomFilter{0xff}, matcher.BloomFilter{0xff, 0xff}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Dice(tt.b)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("Dice() = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+// encodeCSV encodes a CSV and reads the encodings back
+func encodeCSV(t *testing.T, encoder matcher.CLKEncoder, data string) []matcher.EncodedRecord {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := matcher.EncodeCSV(strings.NewReader(data), &buf, encoder); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.ToLower(buf.String()), "baldwin") {
+		t.Fatal("encoded CSV contains cleartext")
+	}
+	records, err := matcher.ReadEncoded(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestMatchEncoded(t *testing.T) {
+	encoder := newEncoder(t, pprlSecret)
+	inputs := encodeCSV(t, encoder, `customer_id,first_name,last_name,street,city,zip_code
+1,Mary,Baldwin,7922 Iron Oak Gardens,Caguas,00725
+2,John,Smith,12 Elm Street,Boston,02118
+`)
+	candidates := encodeCSV(t, encoder, `customer_id,first_name,last_name,street,city,zip_code,phone_number
+7,Marie,Baldwin,7922 Iron Oak Gardens,Caguas,00725,
+8,Jon,Smith,12 Elm St,Boston,02118,
+9,Alice,Wong,1 Pine Rd,Austin,73301,
+`)
+
+	tests := []struct {
+		name     string
+		minScore float64
+		want     map[int]int
+	}{
+		{"Best candidate per input", 50, map[int]int{1: 7, 2: 8}},
+		{"Threshold drops every pair", 99, map[int]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := matcher.DefaultMatchOptions().Merge(matcher.MatchOverrides{TopN: matcher.Ptr(1), MinScore: &tt.minScore})
+			matches, err := matcher.MatchEncoded(inputs, candidates, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[int]int, len(matches))
+			for _, m := range matches {
+				got[m.InputCustomerID] = m.CandidateCustomerID
+				if m.InputLastName != "" || m.CandidateLastName != "" {
+					t.Errorf("match %d-%d carries cleartext", m.InputCustomerID, m.CandidateCustomerID)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("matches = %v, want %v", got, tt.want)
+			}
+			for in, cand := range tt.want {
+				if got[in] != cand {
+					t.Errorf("input %d matched %d, want %d", in, got[in], cand)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchEncodedFilterSizes(t *testing.T) {
+	small, err := matcher.NewCLKEncoder([]byte(pprlSecret), 512, matcher.DefaultFilterHashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []matcher.EncodedRecord{small.Encode(1, map[string]string{"last_name": "Baldwin"})}
+	candidates := []matcher.EncodedRecord{newEncoder(t, pprlSecret).Encode(7, map[string]string{"last_name": "Baldwin"})}
+
+	if _, err := matcher.MatchEncoded(inputs, candidates, matcher.DefaultMatchOptions()); !errors.Is(err, matcher.ErrFilterSize) {
+		t.Errorf("MatchEncoded() error = %v, want ErrFilterSize", err)
+	}
+}
+
+func TestEncodeCSVRequiresCustomerID(t *testing.T) {
+	_, err := matcher.EncodeCSV(strings.NewReader("first_name\nMary\n"), &bytes.Buffer{}, newEncoder(t, pprlSecret))
+	if err == nil {
+		t.Error("EncodeCSV() without a customer_id column succeeded")
+	}
+}
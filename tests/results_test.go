@@ -0,0 +1,113 @@
+package matcher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func TestTopNPerInput(t *testing.T) {
+	candidates := []matcher.Candidate{
+		{InputCustomerID: 1, CandidateCustomerID: 10, Score: 90},
+		{InputCustomerID: 1, CandidateCustomerID: 11, Score: 95},
+		{InputCustomerID: 1, CandidateCustomerID: 12, Score: 80},
+		{InputCustomerID: 2, CandidateCustomerID: 20, Score: 15},
+		{InputCustomerID: 1, CandidateCustomerID: 13, Score: 85},
+	}
+
+	result := matcher.TopNPerInput(candidates, 2)
+
+	want := []struct{ input, candidate int }{{1, 11}, {1, 10}, {2, 20}}
+	if len(result) != len(want) {
+		t.Fatalf("TopNPerInput() returned %d candidates, want %d", len(result), len(want))
+	}
+	for i, w := range want {
+		if result[i].InputCustomerID != w.input || result[i].CandidateCustomerID != w.candidate {
+			t.Errorf("TopNPerInput()[%d] = %d->%d, want %d->%d", i,
+				result[i].InputCustomerID, result[i].CandidateCustomerID, w.input, w.candidate)
+		}
+	}
+}
+
+func TestGroupByInput(t *testing.T) {
+	inputs := []matcher.InputRecord{{CustomerID: 1}, {CustomerID: 2}, {CustomerID: 3}}
+	candidates := []matcher.Candidate{
+		{InputCustomerID: 1, CandidateCustomerID: 10},
+		{InputCustomerID: 3, CandidateCustomerID: 30},
+		{InputCustomerID: 3, CandidateCustomerID: 31},
+	}
+
+	results := matcher.GroupByInput(inputs, candidates)
+
+	if len(results) != len(inputs) {
+		t.Fatalf("GroupByInput() returned %d results, want %d", len(results), len(inputs))
+	}
+	if !results[0].Matched || len(results[0].Candidates) != 1 {
+		t.Errorf("input 1 = %+v, want one candidate", results[0])
+	}
+	if results[1].Matched || results[1].Candidates == nil || len(results[1].Candidates) != 0 {
+		t.Errorf("input 2 = %+v, want an empty no-match entry", results[1])
+	}
+	if !results[2].Matched || len(results[2].Candidates) != 2 {
+		t.Errorf("input 3 = %+v, want two candidates", results[2])
+	}
+}
+
+func TestRankByScore(t *testing.T) {
+	candidates := []matcher.Candidate{
+		{InputCustomerID: 2, CandidateCustomerID: 20, Score: 40, Rank: 1},
+		{InputCustomerID: 1, CandidateCustomerID: 10, Score: 60, Rank: 1},
+		{InputCustomerID: 1, CandidateCustomerID: 11, Score: 95, Rank: 2},
+		{InputCustomerID: 1, CandidateCustomerID: 12, Score: 80, Rank: 3},
+	}
+
+	matcher.RankByScore(candidates)
+
+	want := []struct{ input, candidate, rank int }{{1, 11, 1}, {1, 12, 2}, {1, 10, 3}, {2, 20, 1}}
+	for i, w := range want {
+		c := candidates[i]
+		if c.InputCustomerID != w.input || c.CandidateCustomerID != w.candidate || c.Rank != w.rank {
+			t.Errorf("RankByScore()[%d] = %d->%d rank %d, want %d->%d rank %d", i,
+				c.InputCustomerID, c.CandidateCustomerID, c.Rank, w.input, w.candidate, w.rank)
+		}
+	}
+}
+
+func TestStoreMatchResultsKeepsScoredCandidates(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	runID, err := matcher.CreateNewRun(ctx, pool, "match results test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scored := []matcher.Candidate{
+		{InputCustomerID: 1, CandidateCustomerID: 10, Score: 95, Rank: 1, Returned: true},
+		{InputCustomerID: 1, CandidateCustomerID: 11, Score: 50, Rank: 2},
+	}
+	if err := matcher.StoreMatchResults(ctx, pool, runID, "vector", scored); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter matcher.MatchResultFilter
+		want   int
+	}{
+		{"Every scored candidate", matcher.MatchResultFilter{}, 2},
+		{"Returned only", matcher.MatchResultFilter{Returned: true}, 1},
+		{"Above min score", matcher.MatchResultFilter{MinScore: 60}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := matcher.LoadMatchResults(ctx, pool, runID, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("LoadMatchResults() returned %d results, want %d", len(results), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package matcher_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+)
+
+// startServer serves handler on a local port and returns its address and
+// the result of Serve
+func startServer(t *testing.T, handler http.Handler, opts api.ServerOptions) (*api.Server, string, <-chan error) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := api.NewServer(handler, opts)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(l) }()
+	return server, l.Addr().String(), served
+}
+
+func TestServerDrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	server, addr, served := startServer(t, handler, api.ServerOptions{})
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown() returned %v before the request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if got := <-status; got != http.StatusOK {
+		t.Errorf("in-flight request status = %d, want 200", got)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v, want nil after shutdown", err)
+	}
+}
+
+func TestServerCancelsRequestsAtDeadline(t *testing.T) {
+	started, canceled := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		close(canceled)
+	})
+	server, addr, _ := startServer(t, handler, api.ServerOptions{})
+
+	go http.Get("http://" + addr)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want DeadlineExceeded", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("request context was not canceled at the shutdown deadline")
+	}
+}
+
+// writeCert writes a self-signed certificate for 127.0.0.1 and returns the
+// certificate and key files
+func writeCert(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestServerTLS(t *testing.T) {
+	certFile, keyFile := writeCert(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	server, addr, _ := startServer(t, handler, api.ServerOptions{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	defer server.Shutdown(context.Background())
+	if !server.TLS() {
+		t.Fatal("TLS() = false with a certificate and key")
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 over TLS", resp.StatusCode)
+	}
+}
@@ -0,0 +1,101 @@
+package matcher_test
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func TestLookupStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"Default strategy", "", matcher.DefaultStrategy, false},
+		{"Vector strategy", "vector", "vector", false},
+		{"TF-IDF strategy", "tfidf", "tfidf", false},
+		{"Binary key strategy", "bin_key", "bin_key", false},
+		{"Unknown strategy", "soundex", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := matcher.LookupStrategy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupStrategy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if strategy.Name != tt.expected {
+				t.Errorf("LookupStrategy(%q) = %q, want %q", tt.input, strategy.Name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStrategyArgsMatchPlaceholders(t *testing.T) {
+	placeholder := regexp.MustCompile(`\$(\d+)`)
+	for _, strategy := range matcher.Strategies() {
+		t.Run(strategy.Name, func(t *testing.T) {
+			if strings.Contains(strategy.SQL, "{{") {
+				t.Fatalf("strategy %s SQL is not fully rendered", strategy.Name)
+			}
+			highest := 0
+			for _, m := range placeholder.FindAllStringSubmatch(strategy.SQL, -1) {
+				n, _ := strconv.Atoi(m[1])
+				if n > highest {
+					highest = n
+				}
+			}
+			args := strategy.Args(1, "acme", matcher.DefaultMatchOptions())
+			if len(args) != highest {
+				t.Errorf("strategy %s passes %d args for %d placeholders", strategy.Name, len(args), highest)
+			}
+		})
+	}
+}
+
+func TestExactDuplicateScoresUnderEveryStrategy(t *testing.T) {
+	configured := matcher.PersonScoringProfile()
+	for _, strategy := range matcher.Strategies() {
+		for _, profile := range []*matcher.ScoringProfile{nil, &configured} {
+			// Strategies without a vector distance report 1, the largest
+			similarity := 1.0
+			if strategy.Name == "vector" {
+				similarity = 0
+			}
+			candidate := matcher.Candidate{
+				InputFirstName: "John", InputLastName: "Doe", InputStreet: "123 Main St",
+				InputCity: "Springfield", InputZipCode: "12345", InputPhoneNumber: "5550100100",
+				CandidateFirstName: "John", CandidateLastName: "Doe", CandidateStreet: "123 Main St",
+				CandidateCity: "Springfield", CandidateZipCode: "12345", CandidatePhoneNumber: "5550100100",
+				Similarity: similarity, TfidfScore: 1, BinKeyMatch: true,
+			}
+			opts := matcher.DefaultMatchOptions()
+			opts.Profile = profile
+			matcher.ScoreCandidate(&candidate, strategy, opts)
+			if candidate.Score < 99 {
+				t.Errorf("strategy %s (configured profile %v): exact duplicate score = %v, want about 100", strategy.Name, profile != nil, candidate.Score)
+			}
+		}
+	}
+}
+
+func TestScoringProfileWithoutSimilarity(t *testing.T) {
+	person := matcher.PersonScoringProfile()
+	p := person.WithoutSimilarity()
+	if p.Similarity != 0 {
+		t.Errorf("Similarity = %v, want 0", p.Similarity)
+	}
+	total := p.Tfidf + p.FirstName + p.LastName + p.Street + p.City + p.PhoneNumber + p.ZipCode + p.BinKeyMatch
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("weights sum to %v, want 1", total)
+	}
+	if math.Abs(p.FirstName/p.Tfidf-person.FirstName/person.Tfidf) > 1e-9 {
+		t.Errorf("weights were not scaled evenly: %+v", p)
+	}
+}
@@ -0,0 +1,142 @@
+package matcher_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func sourceRecord(id int, source string, day int, street, city, phone string) matcher.SourceRecord {
+	return matcher.SourceRecord{
+		CustomerID:   id,
+		SourceSystem: source,
+		UpdatedAt:    time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+		Fields:       map[string]string{"street": street, "city": city, "phone_number": phone},
+	}
+}
+
+func TestBuildGoldenRecord(t *testing.T) {
+	members := []matcher.SourceRecord{
+		sourceRecord(1, "web", 3, "1 main st", "springfield", ""),
+		sourceRecord(2, "crm", 1, "1 main street", "springfeld", "555-0100"),
+		sourceRecord(3, "billing", 2, "1 main st apt 2", "springfield", "555-0199"),
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		field    string
+		expected string
+		sourceID int
+	}{
+		{"Most recent non-empty value", matcher.RuleMostRecent, "phone_number", "555-0199", 3},
+		{"Most frequent value", matcher.RuleMostFrequent, "city", "springfield", 1},
+		{"Longest value", matcher.RuleLongest, "street", "1 main st apt 2", 3},
+		{"Preferred source", matcher.RulePreferredSource, "street", "1 main street", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := matcher.SurvivorshipRules{
+				Default:        matcher.RuleLongest,
+				Fields:         map[string]string{tt.field: tt.rule},
+				SourcePriority: []string{"crm", "billing"},
+			}
+			if err := rules.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			golden := matcher.BuildGoldenRecord(1, members, rules)
+			for _, l := range golden.Lineage {
+				if l.Field != tt.field {
+					continue
+				}
+				if l.Value != tt.expected || l.SourceCustomerID != tt.sourceID || l.Rule != tt.rule {
+					t.Errorf("lineage = %+v, want value %q from %d", l, tt.expected, tt.sourceID)
+				}
+				return
+			}
+			t.Errorf("no lineage for field %s", tt.field)
+		})
+	}
+}
+
+func TestSurvivorshipRulesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   matcher.SurvivorshipRules
+		wantErr bool
+	}{
+		{"Defaults", matcher.DefaultSurvivorshipRules(), false},
+		{"Unknown rule", matcher.SurvivorshipRules{Default: "newest"}, true},
+		{"Unknown field", matcher.SurvivorshipRules{Default: matcher.RuleLongest, Fields: map[string]string{"email": matcher.RuleLongest}}, true},
+		{"Preferred source without priority", matcher.SurvivorshipRules{Default: matcher.RulePreferredSource}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSurvivorshipCheckProvenance(t *testing.T) {
+	known := sourceRecord(1, "crm", 2, "1 main st", "springfield", "")
+	unknown := matcher.SourceRecord{CustomerID: 2, Fields: map[string]string{"street": "1 main street"}}
+
+	tests := []struct {
+		name    string
+		rule    string
+		members []matcher.SourceRecord
+		wantErr bool
+	}{
+		{"Most recent with update times", matcher.RuleMostRecent, []matcher.SourceRecord{known}, false},
+		{"Most recent without update time", matcher.RuleMostRecent, []matcher.SourceRecord{known, unknown}, true},
+		{"Preferred source without source", matcher.RulePreferredSource, []matcher.SourceRecord{known, unknown}, true},
+		{"Longest needs no provenance", matcher.RuleLongest, []matcher.SourceRecord{known, unknown}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := matcher.SurvivorshipRules{Default: matcher.RuleLongest, Fields: map[string]string{"street": tt.rule}, SourcePriority: []string{"crm"}}
+			err := rules.CheckProvenance(tt.members)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckProvenance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, matcher.ErrInvalidRequest) {
+				t.Errorf("CheckProvenance() error %v is not ErrInvalidRequest", err)
+			}
+		})
+	}
+}
+
+func TestGoldenRecordRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  [][]int
+		wantErr bool
+	}{
+		{"Entity run", nil, false},
+		{"Disjoint groups", [][]int{{1, 7}, {3, 9, 12}}, false},
+		{"Empty group", [][]int{{1, 7}, {}}, true},
+		{"Overlapping groups", [][]int{{1, 7}, {7, 9}}, true},
+		{"Shared lowest id", [][]int{{1, 7}, {1, 9}}, true},
+		{"Repeated id in a group", [][]int{{3, 3}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := matcher.GoldenRecordRequest{Groups: tt.groups}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, matcher.ErrInvalidRequest) {
+				t.Errorf("Validate() error %v is not ErrInvalidRequest", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package matcher_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTenantFromContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{"No tenant", context.Background(), matcher.DefaultTenant},
+		{"Empty tenant", matcher.WithTenant(context.Background(), ""), matcher.DefaultTenant},
+		{"Named tenant", matcher.WithTenant(context.Background(), "acme"), "acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.TenantFromContext(tt.ctx); got != tt.expected {
+				t.Errorf("TenantFromContext() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		candidate string
+		wantErr   bool
+	}{
+		{"Same tenant", "acme", "acme", false},
+		{"Candidate of another tenant", "acme", "globex", true},
+		{"Input of another tenant", "globex", "acme", true},
+		{"Pair of another tenant", "globex", "globex", true},
+		{"Untagged rows", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := matcher.Candidate{InputCustomerID: 1, CandidateCustomerID: 2, InputTenantID: tt.input, CandidateTenantID: tt.candidate}
+			err := matcher.CheckTenant("acme", c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckTenant() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, matcher.ErrCrossTenant) {
+				t.Errorf("CheckTenant() error = %v, want ErrCrossTenant", err)
+			}
+		})
+	}
+}
+
+// Every strategy must return the tenant of both records so that
+// CheckTenant can reject pairs leaking across tenants
+func TestStrategiesScopedToTenant(t *testing.T) {
+	for _, strategy := range matcher.Strategies() {
+		t.Run(strategy.Name, func(t *testing.T) {
+			for _, column := range []string{"input_tenant_id", "candidate_tenant_id"} {
+				if !strings.Contains(strategy.SQL, column) {
+					t.Errorf("strategy %s does not select %s", strategy.Name, column)
+				}
+			}
+			found := false
+			for _, arg := range strategy.Args(1, "acme", matcher.DefaultMatchOptions()) {
+				if arg == "acme" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("strategy %s does not pass the tenant", strategy.Name)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareSetsTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := api.StaticKeys{
+		api.HashAPIKey("acme-key"):    {Subject: "acme-svc", Scopes: []string{api.ScopeMatchRead}, Tenant: "acme"},
+		api.HashAPIKey("default-key"): {Subject: "svc", Scopes: []string{api.ScopeMatchRead}},
+	}
+	router := gin.New()
+	router.GET("/tenant", api.Authenticate(api.APIKeyAuthenticator{Keys: keys}), func(c *gin.Context) {
+		c.String(http.StatusOK, matcher.TenantFromContext(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{"Key of a tenant", "acme-key", "acme"},
+		{"Key without tenant", "default-key", matcher.DefaultTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+			req.Header.Set(api.APIKeyHeader, tt.key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != tt.expected {
+				t.Errorf("got %d %q, want tenant %q", w.Code, w.Body.String(), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package matcher_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/tracing"
+	"github.com/TFMV/AddressMatchPro/pkg/api"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs an in-memory tracer provider for the test
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func spanAttr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := recordSpans(t)
+	router := newLimitedRouter(api.Limits{})
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantSpan    string
+		wantStatus  int64
+	}{
+		{"Static route", "/api/v1/healthz", "", "GET /api/v1/healthz", http.StatusOK},
+		{"Route pattern instead of path", "/api/v1/entities/abc", "", "GET /api/v1/entities/:customer_id", http.StatusBadRequest},
+		{"Continues the caller's trace", "/api/v1/healthz", "00-" + traceID + "-00f067aa0ba902b7-01", "GET /api/v1/healthz", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != tt.wantSpan || span.SpanKind != trace.SpanKindServer {
+				t.Errorf("span = %q (%v), want server span %q", span.Name, span.SpanKind, tt.wantSpan)
+			}
+			if status, _ := spanAttr(span, "http.response.status_code"); status.AsInt64() != tt.wantStatus {
+				t.Errorf("http.response.status_code = %v, want %d", status.AsInt64(), tt.wantStatus)
+			}
+			if tt.traceparent != "" && span.SpanContext.TraceID().String() != traceID {
+				t.Errorf("trace id = %s, want the caller's %s", span.SpanContext.TraceID(), traceID)
+			}
+		})
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	exporter := recordSpans(t)
+	tracer := tracing.QueryTracer{}
+
+	tests := []struct {
+		name      string
+		tag       string
+		err       error
+		wantRows  int64
+		wantError bool
+	}{
+		{"Successful query", "SELECT 3", nil, 3, false},
+		{"Failed query", "", errors.New("relation does not exist"), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT customer_id FROM customer_matching"})
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag(tt.tag), Err: tt.err})
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 || spans[0].Name != "db.query" {
+				t.Fatalf("spans = %+v, want one db.query span", spans)
+			}
+			if stmt, _ := spanAttr(spans[0], "db.statement"); stmt.AsString() != "SELECT customer_id FROM customer_matching" {
+				t.Errorf("db.statement = %q", stmt.AsString())
+			}
+			if rows, _ := spanAttr(spans[0], "db.rows_affected"); rows.AsInt64() != tt.wantRows {
+				t.Errorf("db.rows_affected = %d, want %d", rows.AsInt64(), tt.wantRows)
+			}
+			if (spans[0].Status.Code == codes.Error) != tt.wantError {
+				t.Errorf("status = %v, want error %v", spans[0].Status, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTracingSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{"Disabled", tracing.ExporterNone, false},
+		{"Unset", "", false},
+		{"Unknown exporter", "zipkin", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tt.exporter})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Setup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err := shutdown(context.Background()); err != nil {
+					t.Errorf("shutdown() error = %v", err)
+				}
+			}
+		})
+	}
+}
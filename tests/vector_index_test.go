@@ -0,0 +1,30 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/TFMV/AddressMatchPro/internal/matcher"
+)
+
+func TestIndexOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    matcher.IndexOptions
+		wantErr bool
+	}{
+		{"Defaults", matcher.DefaultIndexOptions(), false},
+		{"Disabled", matcher.IndexOptions{Method: matcher.IndexMethodNone}, false},
+		{"IVFFlat", matcher.IndexOptions{Method: matcher.IndexMethodIVFFlat, Lists: 100}, false},
+		{"IVFFlat without lists", matcher.IndexOptions{Method: matcher.IndexMethodIVFFlat}, true},
+		{"HNSW ef_construction too small", matcher.IndexOptions{Method: matcher.IndexMethodHNSW, M: 16, EfConstruction: 16}, true},
+		{"Unknown method", matcher.IndexOptions{Method: "lsh"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}